@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xpzouying/try/internal/action"
+	"github.com/xpzouying/try/internal/archive"
+	"github.com/xpzouying/try/internal/du"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/meta"
+	"github.com/xpzouying/try/internal/selector"
+)
+
+// runArchive packs experiments into archives and removes the originals.
+func runArchive(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: try archive <name>...")
+	}
+	var errs []error
+	for _, name := range args {
+		e, err := findEntry(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, archiveEntry(selector.Result{
+			Action:     "archive",
+			Path:       e.Path,
+			BaseName:   e.Name,
+			IsWorktree: e.IsWorktree,
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// runRestore unpacks an archived experiment, or lists archives without a name.
+func runRestore(args []string) error {
+	archived, err := entry.LoadArchived()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		for _, e := range archived {
+			name := e.Name
+			if e.Profile != "" {
+				name = "[" + e.Profile + "] " + name
+			}
+			size := int64(0)
+			if info, err := os.Stat(e.Path); err == nil {
+				size = info.Size()
+			}
+			fmt.Printf("%5s  %s  %s\n", du.Format(size), e.ModTime.Format("2006-01-02"), name)
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, name := range args {
+		e, err := findArchived(archived, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, err = restoreArchive(e.Path)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// findArchived picks the archive named name, or the only one whose name
+// contains it.
+func findArchived(archived []*entry.Entry, name string) (*entry.Entry, error) {
+	var matches []*entry.Entry
+	for _, e := range archived {
+		if e.Name == name {
+			return e, nil
+		}
+		if strings.Contains(e.Name, name) {
+			matches = append(matches, e)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no archived experiment matches %s", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, e := range matches {
+			names[i] = e.Name
+		}
+		return nil, fmt.Errorf("%s matches several archives: %s", name, strings.Join(names, ", "))
+	}
+}
+
+// archiveEntry packs an entry and its metadata into the root's archive
+// directory, then removes the original. Worktrees belong to their source
+// repository and graduated symlinks to their project; both are refused.
+func archiveEntry(r selector.Result) error {
+	if r.IsWorktree || action.IsWorktree(r.Path) {
+		return fmt.Errorf("archive %s: worktrees can't be archived; delete or graduate it instead", r.BaseName)
+	}
+	if info, err := os.Lstat(r.Path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("archive %s: already graduated; demote it first", r.BaseName)
+	}
+	if err := entry.CheckContained(r.Path); err != nil {
+		return fmt.Errorf("archive %s: %w", r.BaseName, err)
+	}
+	root := filepath.Dir(r.Path)
+	m, _ := meta.Load(root, r.BaseName)
+
+	dst := archive.PathFor(root, r.BaseName)
+	if err := archive.Create(r.Path, dst, m); err != nil {
+		return fmt.Errorf("archive %s: %w", r.BaseName, err)
+	}
+	if err := action.Delete(r.Path); err != nil {
+		return fmt.Errorf("archive %s: archived to %s but removing the original failed: %w", r.BaseName, dst, err)
+	}
+	// The archive carries the metadata; restore puts it back
+	if err := meta.Remove(root, r.BaseName); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: remove metadata: %v\n", err)
+	}
+
+	size := int64(0)
+	if info, err := os.Stat(dst); err == nil {
+		size = info.Size()
+	}
+	fmt.Fprintf(os.Stderr, "Archived: %s → %s (%s)\n", r.BaseName, dst, du.Format(size))
+	return nil
+}
+
+// restoreArchive unpacks the archive at path back into its tries root,
+// restores its metadata and removes the archive. It returns the restored
+// directory.
+func restoreArchive(path string) (string, error) {
+	root := archive.Root(path)
+	name := strings.TrimSuffix(filepath.Base(path), archive.Ext)
+	dest := filepath.Join(root, name)
+	if err := entry.CheckContained(dest); err != nil {
+		return "", fmt.Errorf("restore %s: %w", name, err)
+	}
+
+	m, err := archive.Extract(path, dest)
+	if err != nil {
+		return "", fmt.Errorf("restore %s: %w", name, err)
+	}
+	if m != nil {
+		if err := meta.Save(root, name, m); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: restore metadata: %v\n", err)
+		}
+	}
+	if err := os.Remove(path); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: remove archive: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "Restored: %s → %s\n", name, dest)
+	return dest, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xpzouying/try/internal/archive"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+func TestRun_ArchiveRestore(t *testing.T) {
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+
+	name := "2024-01-15-redis"
+	dir := filepath.Join(triesPath, name)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := meta.Save(triesPath, name, &meta.Meta{Description: "cache test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := captureRun(t, "archive", name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatal("expected original removed")
+	}
+	if m, _ := meta.Load(triesPath, name); m != nil {
+		t.Error("expected metadata moved into the archive")
+	}
+
+	out, err := captureRun(t, "restore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, name) {
+		t.Errorf("expected archive listed, got %q", out)
+	}
+
+	if _, err := captureRun(t, "restore", "redis"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "notes.md"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("notes.md not restored: %q, %v", data, err)
+	}
+	if m, _ := meta.Load(triesPath, name); m == nil || m.Description != "cache test" {
+		t.Errorf("metadata not restored: %+v", m)
+	}
+	if _, err := os.Stat(archive.PathFor(triesPath, name)); !os.IsNotExist(err) {
+		t.Error("expected archive removed after restore")
+	}
+
+	if _, err := captureRun(t, "restore", "redis"); err == nil {
+		t.Error("expected error restoring a missing archive")
+	}
+}
+
+func TestRun_ArchiveRefusesGraduated(t *testing.T) {
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+
+	project := filepath.Join(t.TempDir(), "redis")
+	if err := os.Mkdir(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(triesPath, "2024-01-15-redis")
+	if err := os.Symlink(project, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := captureRun(t, "archive", "2024-01-15-redis"); err == nil || !strings.Contains(err.Error(), "graduated") {
+		t.Errorf("expected archiving a graduated entry to be refused, got %v", err)
+	}
+	if _, err := os.Lstat(link); err != nil {
+		t.Error("the symlink should be kept")
+	}
+	if _, err := os.Stat(archive.PathFor(triesPath, "2024-01-15-redis")); !os.IsNotExist(err) {
+		t.Error("no archive should be created")
+	}
+}
+
+func TestFindArchived(t *testing.T) {
+	archived := []*entry.Entry{
+		{Name: "2024-01-15-redis"},
+		{Name: "2024-01-16-redis-cluster"},
+	}
+	if e, err := findArchived(archived, "2024-01-15-redis"); err != nil || e.Name != "2024-01-15-redis" {
+		t.Errorf("exact name: %v, %v", e, err)
+	}
+	if e, err := findArchived(archived, "cluster"); err != nil || e.Name != "2024-01-16-redis-cluster" {
+		t.Errorf("unique substring: %v, %v", e, err)
+	}
+	if _, err := findArchived(archived, "redis"); err == nil {
+		t.Error("expected ambiguity error")
+	}
+}
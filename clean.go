@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xpzouying/try/internal/clean"
+	"github.com/xpzouying/try/internal/config"
+	"github.com/xpzouying/try/internal/du"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/gitstatus"
+)
+
+// runClean removes regenerable directories (clean.patterns) from
+// experiments untouched for longer than --older-than. Graduated
+// symlinks and git trees with uncommitted changes are skipped, as are
+// matches git tracks (a committed build/ is source, not an artifact).
+func runClean(args []string) error {
+	fs := flag.NewFlagSet("clean", flag.ContinueOnError)
+	olderThan := fs.String("older-than", "30d", "only experiments not modified for this long (e.g. 30d, 2w, 12h)")
+	dryRun := fs.Bool("dry-run", false, "only report what would be removed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	age, err := parseAge(*olderThan)
+	if err != nil {
+		return err
+	}
+	patterns := config.GetList("clean.patterns")
+	if len(patterns) == 0 {
+		return fmt.Errorf("clean.patterns is empty")
+	}
+	if err := clean.CheckPatterns(patterns); err != nil {
+		return fmt.Errorf("clean.patterns: %w", err)
+	}
+
+	entries, err := entry.LoadAll()
+	if err != nil {
+		return fmt.Errorf("load entries: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cutoff := time.Now().Add(-age)
+	var freed int64
+	cleaned := 0
+	for _, e := range entries {
+		if e.ModTime.After(cutoff) {
+			continue
+		}
+		if reason := cleanSkipReason(e.Path); reason != "" {
+			fmt.Fprintf(os.Stderr, "skip %s: %s\n", e.Name, reason)
+			continue
+		}
+
+		matches, err := clean.Find(ctx, e.Path, patterns)
+		if err != nil {
+			return fmt.Errorf("scan %s: %w", e.Name, err)
+		}
+		matches = untrackedMatches(e, matches)
+		if len(matches) == 0 {
+			continue
+		}
+
+		if !*dryRun {
+			for _, m := range matches {
+				if err := entry.CheckContained(m.Path); err != nil {
+					return err
+				}
+				if err := os.RemoveAll(m.Path); err != nil {
+					return fmt.Errorf("remove %s: %w", m.Path, err)
+				}
+			}
+		}
+		writeCleanEntry(os.Stdout, e, matches)
+		for _, m := range matches {
+			freed += m.Size
+		}
+		cleaned++
+	}
+
+	switch {
+	case cleaned == 0:
+		fmt.Println("Nothing to clean")
+	case *dryRun:
+		fmt.Printf("Would free %s in %d experiments (dry run)\n", du.Format(freed), cleaned)
+	default:
+		fmt.Printf("Freed %s in %d experiments\n", du.Format(freed), cleaned)
+	}
+	return nil
+}
+
+// cleanSkipReason explains why the experiment at path must not be
+// cleaned, or returns "" if it may be.
+func cleanSkipReason(path string) string {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err.Error()
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return "graduated symlink"
+	}
+	if !gitstatus.IsRepo(path) {
+		return ""
+	}
+	status, err := gitstatus.Get(path)
+	if err != nil {
+		return fmt.Sprintf("git status: %v", err)
+	}
+	if !status.Clean() {
+		return "uncommitted changes"
+	}
+	return ""
+}
+
+// untrackedMatches drops the matches that contain files git tracks,
+// reporting each one. Outside git trees every match is kept.
+func untrackedMatches(e *entry.Entry, matches []clean.Match) []clean.Match {
+	if len(matches) == 0 || !gitstatus.IsRepo(e.Path) {
+		return matches
+	}
+	var kept []clean.Match
+	for _, m := range matches {
+		rel, _ := filepath.Rel(e.Path, m.Path)
+		tracked, err := gitstatus.Tracked(e.Path, m.Path)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "skip %s/%s: git ls-files: %v\n", e.Name, rel, err)
+		case tracked:
+			fmt.Fprintf(os.Stderr, "skip %s/%s: tracked by git\n", e.Name, rel)
+		default:
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// writeCleanEntry prints an experiment and its matches with sizes.
+func writeCleanEntry(w io.Writer, e *entry.Entry, matches []clean.Match) {
+	name := e.Name
+	if e.Profile != "" {
+		name = "[" + e.Profile + "] " + name
+	}
+	fmt.Fprintln(w, name)
+	for _, m := range matches {
+		rel, err := filepath.Rel(e.Path, m.Path)
+		if err != nil {
+			rel = m.Path
+		}
+		fmt.Fprintf(w, "  %5s  %s\n", du.Format(m.Size), rel)
+	}
+}
+
+// parseAge parses an age such as "90d", "2w" or a Go duration like "36h".
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			days, err := strconv.Atoi(n)
+			if err != nil || days < 0 {
+				return 0, fmt.Errorf("invalid age: %s (e.g. 30d, 2w, 12h)", s)
+			}
+			return time.Duration(days) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age: %s (e.g. 30d, 2w, 12h)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"90d", 90 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"36h", 36 * time.Hour},
+		{"0d", 0},
+	}
+	for _, tt := range tests {
+		got, err := parseAge(tt.input)
+		if err != nil || got != tt.expected {
+			t.Errorf("parseAge(%q) = %v, %v; expected %v", tt.input, got, err, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "d", "-3d", "ten days", "5x"} {
+		if _, err := parseAge(input); err == nil {
+			t.Errorf("parseAge(%q) expected error", input)
+		}
+	}
+}
+
+func TestRun_Clean(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+	old := time.Now().Add(-60 * 24 * time.Hour)
+
+	mkEntry := func(name string, mtime time.Time) string {
+		dir := filepath.Join(triesPath, name)
+		if err := os.MkdirAll(filepath.Join(dir, "node_modules", "pkg"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "node_modules", "pkg", "index.js"), make([]byte, 2048), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(dir, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	stale := mkEntry("2024-01-10-stale", old)
+	recent := mkEntry("2024-01-11-recent", time.Now())
+	dirty := mkEntry("2024-01-12-dirty", time.Now())
+	if out, err := exec.Command("git", "-C", dirty, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	if err := os.Chtimes(dirty, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Graduated symlink pointing at an old project with artifacts
+	project := mkEntry("../project", old)
+	if err := os.Symlink(project, filepath.Join(triesPath, "2024-01-09-graduated")); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureRun(t, "clean", "--older-than", "30d", "--dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "2024-01-10-stale\n   2.0K  node_modules\n") || !strings.Contains(out, "Would free 2.0K in 1 experiments") {
+		t.Errorf("unexpected dry run output:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(stale, "node_modules")); err != nil {
+		t.Fatal("dry run removed files")
+	}
+
+	if _, err := captureRun(t, "clean", "--older-than", "30d"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(stale, "node_modules")); !os.IsNotExist(err) {
+		t.Error("expected node_modules removed from stale entry")
+	}
+	for _, dir := range []string{recent, dirty, project} {
+		if _, err := os.Stat(filepath.Join(dir, "node_modules")); err != nil {
+			t.Errorf("expected %s left alone", dir)
+		}
+	}
+}
+
+func TestRun_CleanKeepsTracked(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+	old := time.Now().Add(-60 * 24 * time.Hour)
+
+	dir := filepath.Join(triesPath, "2024-01-10-app")
+	for _, f := range []string{"build/build.sh", "dist/app.js", ".gitignore"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "build", "build.sh"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dist", "app.js"), make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("dist/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=try", "-c", "user.email=try@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.Chtimes(dir, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureRun(t, "clean", "--older-than", "30d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Freed 2.0K in 1 experiments") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "build", "build.sh")); err != nil {
+		t.Error("tracked build/ should be kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dist")); !os.IsNotExist(err) {
+		t.Error("ignored dist/ should be removed")
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xpzouying/try/internal/config"
+)
+
+// runConfig inspects and edits the config file.
+//
+//	try config path               Print the config file location
+//	try config list [--show-origin] Print every key's effective value
+//	try config get <key>          Print one effective value
+//	try config set <key> <value>  Write a value to the config file
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("config requires a subcommand: path, list, get or set")
+	}
+
+	switch args[0] {
+	case "path":
+		fmt.Println(config.Current().Path())
+		return nil
+	case "list":
+		fs := flag.NewFlagSet("config list", flag.ContinueOnError)
+		showOrigin := fs.Bool("show-origin", false, "show where each value comes from")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return listConfig(*showOrigin)
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: try config get <key>")
+		}
+		if _, ok := config.Lookup(args[1]); !ok {
+			return fmt.Errorf("unknown config key: %s (see try config list)", args[1])
+		}
+		fmt.Println(config.Get(args[1]))
+		return nil
+	case "set":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: try config set <key> <value>")
+		}
+		c := config.Current()
+		if err := c.Set(args[1], args[2]); err != nil {
+			return err
+		}
+		if value, source := c.Resolve(args[1]); source == config.SourceEnv || source == config.SourceFlag {
+			k, _ := config.Lookup(args[1])
+			fmt.Fprintf(os.Stderr, "note: %s is overridden by %s (currently %q)\n", args[1], originLabel(k, source), value)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown config subcommand: %s (supported: path, list, get, set)", args[0])
+	}
+}
+
+func listConfig(showOrigin bool) error {
+	c := config.Current()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, k := range c.AllKeys() {
+		value, source := c.Resolve(k.Name)
+		if showOrigin {
+			fmt.Fprintf(tw, "%s\t%s=%s\n", originLabel(k, source), k.Name, value)
+		} else {
+			fmt.Fprintf(tw, "%s=%s\n", k.Name, value)
+		}
+	}
+	return tw.Flush()
+}
+
+// originLabel names the source of a value, e.g. "env:TRY_PATH".
+func originLabel(k config.Key, source config.Source) string {
+	switch source {
+	case config.SourceEnv:
+		return "env:" + k.EnvName()
+	case config.SourceFile:
+		return "file:" + config.Current().Path()
+	case config.SourceFlag:
+		return "flag:-c"
+	default:
+		return "default"
+	}
+}
+
+// parseOverrides strips leading `-c key=value` and `--profile name`
+// flags from args, returning them as config overrides.
+func parseOverrides(args []string) ([]string, []string, error) {
+	var overrides []string
+	for len(args) > 0 {
+		switch {
+		case args[0] == "-c" || args[0] == "--profile":
+			if len(args) < 2 {
+				return nil, nil, fmt.Errorf("%s requires an argument", args[0])
+			}
+			if args[0] == "-c" {
+				overrides = append(overrides, args[1])
+			} else {
+				overrides = append(overrides, "core.profile="+args[1])
+			}
+			args = args[2:]
+		case strings.HasPrefix(args[0], "-c="):
+			overrides = append(overrides, strings.TrimPrefix(args[0], "-c="))
+			args = args[1:]
+		case strings.HasPrefix(args[0], "--profile="):
+			overrides = append(overrides, "core.profile="+strings.TrimPrefix(args[0], "--profile="))
+			args = args[1:]
+		default:
+			return args, overrides, nil
+		}
+	}
+	return args, overrides, nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureRun runs try with args and returns what it printed to stdout.
+func captureRun(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(args)
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	return string(out), err
+}
+
+func TestRun_ConfigSetGet(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	t.Setenv("TRY_CONFIG", configPath)
+	t.Setenv("TRY_PATH", "")
+
+	if _, err := captureRun(t, "config", "set", "core.path", "/tmp/lab"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := captureRun(t, "config", "get", "core.path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "/tmp/lab" {
+		t.Errorf("expected value from file, got %q", out)
+	}
+
+	out, err = captureRun(t, "config", "path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != configPath {
+		t.Errorf("expected %s, got %q", configPath, out)
+	}
+
+	// Env beats file, -c beats env
+	t.Setenv("TRY_PATH", "/tmp/env")
+	out, _ = captureRun(t, "config", "get", "core.path")
+	if strings.TrimSpace(out) != "/tmp/env" {
+		t.Errorf("expected env value, got %q", out)
+	}
+	out, _ = captureRun(t, "-c", "core.path=/tmp/flag", "config", "get", "core.path")
+	if strings.TrimSpace(out) != "/tmp/flag" {
+		t.Errorf("expected flag value, got %q", out)
+	}
+}
+
+func TestRun_ConfigList(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "config.toml"))
+	t.Setenv("TRY_PATH", "/tmp/env")
+
+	out, err := captureRun(t, "config", "list", "--show-origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "env:TRY_PATH") || !strings.Contains(out, "core.path=/tmp/env") {
+		t.Errorf("expected env origin for core.path, got:\n%s", out)
+	}
+	if !strings.Contains(out, "default") || !strings.Contains(out, "keys.new=ctrl+t") {
+		t.Errorf("expected defaults listed, got:\n%s", out)
+	}
+}
+
+func TestRun_ConfigErrors(t *testing.T) {
+	t.Setenv("TRY_CONFIG", filepath.Join(t.TempDir(), "config.toml"))
+
+	tests := [][]string{
+		{"config"},
+		{"config", "get", "core.nope"},
+		{"config", "set", "ui.theme", "neon"},
+		{"config", "frobnicate"},
+		{"-c", "core.nope=1", "list"},
+		{"-c"},
+		{"exec", "-c", "core.path=/tmp", "list"},
+	}
+	for _, args := range tests {
+		if _, err := captureRun(t, args...); err == nil {
+			t.Errorf("run(%q) should fail", args)
+		}
+	}
+}
+
+func TestRun_ConfigInvalidFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("[core]\npath = oops\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRY_CONFIG", configPath)
+
+	_, err := captureRun(t, "list")
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected parse error with line number, got %v", err)
+	}
+}
+
+func TestRun_CloneConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := "[clone]\ndepth = 1\nargs = [\"--recurse-submodules\"]\n\n[hooks]\npost_clone = \"git log -1\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRY_CONFIG", configPath)
+	t.Setenv("TRY_PATH", t.TempDir())
+	t.Setenv("TRY_SHELL", "bash")
+
+	out, err := captureRun(t, "clone", "https://github.com/tobi/try")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "git clone --depth 1 --recurse-submodules -- ") {
+		t.Errorf("expected clone options, got %q", out)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(out), "&& git log -1") {
+		t.Errorf("expected hook after cd, got %q", out)
+	}
+}
+
+func TestRun_DateFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+	t.Setenv("TRY_DATE_FORMAT", "20060102")
+	t.Setenv("TRY_SHELL", "bash")
+
+	out, err := captureRun(t, "clone", "https://github.com/tobi/try")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "-01-") || !strings.Contains(out, "-tobi-try") {
+		t.Errorf("expected compact date prefix, got %q", out)
+	}
+}
+
+func TestRun_Profile(t *testing.T) {
+	tmpDir := t.TempDir()
+	work := filepath.Join(tmpDir, "work")
+	oss := filepath.Join(tmpDir, "oss")
+	for _, dir := range []string{filepath.Join(work, "2024-01-15-api"), filepath.Join(oss, "2024-01-16-lib")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configPath := filepath.Join(tmpDir, "config.toml")
+	content := "[profile.work]\npath = \"" + work + "\"\n\n[profile.oss]\npath = \"" + oss + "\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRY_CONFIG", configPath)
+
+	out, err := captureRun(t, "--profile", "oss", "list", "--format", "plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "2024-01-16-lib") || strings.Contains(out, "2024-01-15-api") {
+		t.Errorf("expected only the oss root, got %q", out)
+	}
+
+	t.Setenv("TRY_PROFILE", "all")
+	out, err = captureRun(t, "list", "--format", "plain", "--sort", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "[work] 2024-01-15-api") || !strings.Contains(out, "[oss] 2024-01-16-lib") {
+		t.Errorf("expected merged list with badges, got %q", out)
+	}
+
+	// Entries in any merged root can be addressed by name
+	if _, err := captureRun(t, "tag", "2024-01-15-api", "+backend"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(work, ".try", "meta", "2024-01-15-api.json")); err != nil {
+		t.Errorf("expected metadata in the work root: %v", err)
+	}
+
+	if _, err := captureRun(t, "--profile=home", "list"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xpzouying/try/internal/action"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/frecency"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+// runDemote moves a project back into the tries root: a graduated entry
+// by name, or any directory by path. A graduated project returns under
+// its old name; other directories get today's date prefix.
+func runDemote(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: try demote <name|path>")
+	}
+	entries, err := entry.LoadAll()
+	if err != nil {
+		return fmt.Errorf("load entries: %w", err)
+	}
+
+	src, link, err := demoteSource(entries, args[0])
+	if err != nil {
+		return err
+	}
+	dest := link
+	if dest == "" {
+		dest = filepath.Join(entry.Roots()[0].Path, entry.DatePrefix(time.Now())+"-"+filepath.Base(src))
+	}
+	return demoteEntry(src, dest, link)
+}
+
+// demoteSource resolves arg to the project to move back and the symlink
+// graduate left for it, if any.
+func demoteSource(entries []*entry.Entry, arg string) (src, link string, err error) {
+	for _, e := range entries {
+		if e.Name != arg || !e.Graduated() {
+			continue
+		}
+		if e.Broken {
+			return "", "", fmt.Errorf("%s is a broken link: %s no longer exists", e.Name, e.Target)
+		}
+		return e.Target, e.Path, nil
+	}
+
+	src, err = filepath.Abs(arg)
+	if err != nil {
+		return "", "", err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", "", fmt.Errorf("no graduated entry or directory named %s", arg)
+	}
+	if !info.IsDir() {
+		return "", "", fmt.Errorf("not a directory: %s", src)
+	}
+	if entry.CheckContained(src) == nil {
+		return "", "", fmt.Errorf("already in the tries directory: %s", src)
+	}
+
+	// Reuse the symlink graduate left, so the project returns under its old name
+	real, _ := filepath.EvalSymlinks(src)
+	for _, e := range entries {
+		if !e.Graduated() || e.Broken {
+			continue
+		}
+		if target, _ := filepath.EvalSymlinks(e.Target); target == real {
+			return src, e.Path, nil
+		}
+	}
+	return src, "", nil
+}
+
+// demoteEntry moves src back to dest, replacing the symlink at link if
+// there is one. Metadata and visits recorded under the link follow it.
+// A src that is, or contains, /, $HOME or a tries root is refused.
+func demoteEntry(src, dest, link string) error {
+	if err := entry.CheckSource(src); err != nil {
+		return fmt.Errorf("demote %s: %w", filepath.Base(src), err)
+	}
+	for _, p := range []string{dest, link} {
+		if p == "" {
+			continue
+		}
+		if err := entry.CheckContained(p); err != nil {
+			return fmt.Errorf("demote %s: %w", filepath.Base(src), err)
+		}
+	}
+	if err := action.Demote(src, dest, link); err != nil {
+		return fmt.Errorf("demote %s: %w", filepath.Base(src), err)
+	}
+	if link != "" && link != dest {
+		root, oldName, newName := filepath.Dir(link), filepath.Base(link), filepath.Base(dest)
+		if err := meta.Move(root, oldName, newName); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: move metadata: %v\n", err)
+		}
+		updateVisits(root, func(l *frecency.Log) { l.Rename(oldName, newName) })
+	}
+	// No longer graduated
+	root, name := filepath.Dir(dest), filepath.Base(dest)
+	if m, _ := meta.Load(root, name); m != nil && m.Graduated != nil {
+		m.Graduated = nil
+		if err := meta.Save(root, name, m); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: save metadata: %v\n", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Demoted: %s → %s\n", src, dest)
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/meta"
+	"github.com/xpzouying/try/internal/selector"
+)
+
+func TestRun_Demote(t *testing.T) {
+	tmpDir := t.TempDir()
+	tries := filepath.Join(tmpDir, "tries")
+	t.Setenv("TRY_PATH", tries)
+
+	name := "2024-01-15-redis"
+	project := filepath.Join(tmpDir, "projects", "redis")
+	for _, dir := range []string{filepath.Join(tries, name), filepath.Dir(project)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := meta.Save(tries, name, &meta.Meta{Description: "cache test"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := graduateEntry(selector.Result{Path: filepath.Join(tries, name), DestPath: project, BaseName: name}); err != nil {
+		t.Fatal(err)
+	}
+
+	// By path: the project returns under the symlink's name
+	if _, err := captureRun(t, "demote", project); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(filepath.Join(tries, name)); err != nil || !info.IsDir() {
+		t.Fatalf("expected %s back as a directory: %v", name, err)
+	}
+	if m, _ := meta.Load(tries, name); m == nil || m.Description != "cache test" || m.Graduated != nil {
+		t.Errorf("expected metadata kept without the graduation, got %+v", m)
+	}
+
+	// By name, after graduating again
+	if err := graduateEntry(selector.Result{Path: filepath.Join(tries, name), DestPath: project, BaseName: name}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := captureRun(t, "demote", name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(project); !os.IsNotExist(err) {
+		t.Error("expected project moved back")
+	}
+
+	// Any directory gets today's date prefix
+	other := filepath.Join(tmpDir, "scratch")
+	if err := os.Mkdir(other, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := captureRun(t, "demote", other); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(tries, entry.DatePrefix(time.Now())+"-scratch")
+	if info, err := os.Stat(dest); err != nil || !info.IsDir() {
+		t.Errorf("expected %s: %v", dest, err)
+	}
+}
+
+func TestRun_DemoteRefused(t *testing.T) {
+	tmpDir := t.TempDir()
+	tries := filepath.Join(tmpDir, "tries")
+	t.Setenv("TRY_PATH", tries)
+	if err := os.MkdirAll(filepath.Join(tries, "2024-01-15-redis"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(tmpDir, "gone"), filepath.Join(tries, "2024-01-14-gone")); err != nil {
+		t.Fatal(err)
+	}
+
+	home := filepath.Join(tmpDir, "home")
+	if err := os.Mkdir(home, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+
+	cases := map[string]string{
+		"2024-01-14-gone":                        "broken link",
+		filepath.Join(tries, "2024-01-15-redis"): "already in the tries directory",
+		"missing":                                "no graduated entry",
+		tries:                                    "tries root",
+		tmpDir:                                   "contains",
+		home:                                     "$HOME",
+		"/":                                      "refusing",
+	}
+	for arg, msg := range cases {
+		if _, err := captureRun(t, "demote", arg); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("demote %s: expected %q, got %v", arg, msg, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tries, "2024-01-15-redis")); err != nil {
+		t.Errorf("refused demotes should leave the tries root alone: %v", err)
+	}
+}
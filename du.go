@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/xpzouying/try/internal/du"
+	"github.com/xpzouying/try/internal/entry"
+)
+
+// duWorkers bounds how many experiments are measured at once.
+const duWorkers = 4
+
+// duItem is one measured experiment.
+type duItem struct {
+	Name    string
+	Profile string
+	Size    int64
+}
+
+// runDu prints the biggest experiments and the total disk usage.
+// Ctrl-C stops the walk.
+func runDu(args []string) error {
+	fs := flag.NewFlagSet("du", flag.ContinueOnError)
+	limit := fs.Int("limit", 10, "number of experiments to show (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, err := entry.LoadAll()
+	if err != nil {
+		return fmt.Errorf("load entries: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Graduated entries are symlinks; their projects live elsewhere
+	byPath := map[string]*entry.Entry{}
+	var paths []string
+	for _, e := range entries {
+		if e.Graduated() {
+			continue
+		}
+		byPath[e.Path] = e
+		paths = append(paths, e.Path)
+	}
+
+	var items []duItem
+	for r := range du.SizeAll(ctx, paths, duWorkers) {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", r.Path, r.Err)
+			continue
+		}
+		e := byPath[r.Path]
+		items = append(items, duItem{Name: e.Name, Profile: e.Profile, Size: r.Size})
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("du: %w", err)
+	}
+
+	return writeDu(os.Stdout, items, *limit)
+}
+
+// writeDu prints items biggest first, at most limit of them, then the
+// total over all items.
+func writeDu(w io.Writer, items []duItem, limit int) error {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Size > items[j].Size })
+
+	var total int64
+	for _, it := range items {
+		total += it.Size
+	}
+	shown := items
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	// Formatted sizes are at most 5 wide, e.g. "1023K"
+	for _, it := range shown {
+		name := it.Name
+		if it.Profile != "" {
+			name = "[" + it.Profile + "] " + name
+		}
+		fmt.Fprintf(w, "%5s  %s\n", du.Format(it.Size), name)
+	}
+
+	summary := fmt.Sprintf("%5s  total in %d experiments", du.Format(total), len(items))
+	if len(shown) < len(items) {
+		summary += fmt.Sprintf(" (top %d shown)", len(shown))
+	}
+	_, err := fmt.Fprintln(w, summary)
+	return err
+}
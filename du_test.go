@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteDu(t *testing.T) {
+	items := []duItem{
+		{Name: "2024-01-10-small", Size: 512},
+		{Name: "2024-01-11-big", Size: 3 << 30},
+		{Name: "2024-01-12-medium", Size: 20 << 20, Profile: "work"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDu(&buf, items, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "" +
+		" 3.0G  2024-01-11-big\n" +
+		"  20M  [work] 2024-01-12-medium\n" +
+		" 3.0G  total in 3 experiments (top 2 shown)\n"
+	if buf.String() != expected {
+		t.Errorf("writeDu() =\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestRun_Du(t *testing.T) {
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+
+	for name, size := range map[string]int{"2024-01-10-a": 100, "2024-01-11-b": 5000} {
+		dir := filepath.Join(triesPath, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "data"), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := captureRun(t, "du", "--limit", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "2024-01-11-b") || !strings.Contains(lines[1], "total in 2 experiments") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
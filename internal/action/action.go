@@ -0,0 +1,173 @@
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Graduate moves src to dest and leaves a symlink to dest at link.
+// Worktrees are moved with `git worktree move` so the source repository's
+// bookkeeping stays valid. Across filesystems the project is copied,
+// verified and only then removed, reporting to progress (which may be
+// nil). If creating the symlink fails, the move is undone.
+func Graduate(src, dest, link string, progress Progress) error {
+	if _, err := os.Lstat(dest); err == nil {
+		return fmt.Errorf("destination already exists: %s", dest)
+	}
+	if err := move(src, dest, progress); err != nil {
+		return fmt.Errorf("move %s: %w", filepath.Base(src), err)
+	}
+	if err := os.Symlink(dest, link); err != nil {
+		if rbErr := move(dest, src, nil); rbErr != nil {
+			return fmt.Errorf("create symlink: %w (rollback failed, project left at %s: %v)", err, dest, rbErr)
+		}
+		return fmt.Errorf("create symlink: %w", err)
+	}
+	return nil
+}
+
+// Demote undoes Graduate: it moves the project at src back to dest in the
+// tries directory, removing the symlink at link first (link may equal
+// dest; pass "" when there is none). Worktrees are moved with git. If the
+// move fails, the symlink is put back.
+func Demote(src, dest, link string) error {
+	if link != "" {
+		if err := os.Remove(link); err != nil {
+			return fmt.Errorf("remove symlink: %w", err)
+		}
+	}
+	restoreLink := func(err error) error {
+		if link == "" {
+			return err
+		}
+		if lnErr := os.Symlink(src, link); lnErr != nil {
+			return fmt.Errorf("%w (restoring symlink %s failed: %v)", err, link, lnErr)
+		}
+		return err
+	}
+
+	if _, err := os.Lstat(dest); err == nil {
+		return restoreLink(fmt.Errorf("destination already exists: %s", dest))
+	}
+	if err := move(src, dest, nil); err != nil {
+		return restoreLink(fmt.Errorf("move %s: %w", filepath.Base(src), err))
+	}
+	return nil
+}
+
+// Rename moves src to dest within the tries directory.
+func Rename(src, dest string) error {
+	if _, err := os.Lstat(dest); err == nil {
+		return fmt.Errorf("destination already exists: %s", filepath.Base(dest))
+	}
+	if err := move(src, dest, nil); err != nil {
+		return fmt.Errorf("rename %s: %w", filepath.Base(src), err)
+	}
+	return nil
+}
+
+// Delete removes the directory at path. Worktrees are removed with
+// `git worktree remove --force` so the source repository forgets them.
+// A symlink is removed on its own, never what it points to.
+func Delete(path string) error {
+	if isSymlink(path) {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("delete %s: %w", filepath.Base(path), err)
+		}
+		return nil
+	}
+	if IsWorktree(path) {
+		if err := git(path, "worktree", "remove", "--force", path); err != nil {
+			return fmt.Errorf("remove worktree %s: %w", filepath.Base(path), err)
+		}
+		return nil
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("delete %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// IsWorktree reports whether path is a git worktree (.git is a file, not
+// a directory). A symlink to a worktree is not one itself.
+func IsWorktree(path string) bool {
+	if isSymlink(path) {
+		return false
+	}
+	info, err := os.Lstat(filepath.Join(path, ".git"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// isSymlink reports whether path itself is a symlink.
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// move renames src to dest, using git for worktrees, or copies when
+// dest is on another filesystem. A repository's linked worktrees are
+// pointed at its new location. A symlink is moved as a link, leaving
+// its target where it is.
+func move(src, dest string, progress Progress) error {
+	if isSymlink(src) {
+		return moveLink(src, dest)
+	}
+	if CrossDevice(src, filepath.Dir(dest)) {
+		return copyMove(src, dest, progress)
+	}
+	if IsWorktree(src) {
+		return git(src, "worktree", "move", src, dest)
+	}
+	if err := os.Rename(src, dest); err != nil {
+		if isCrossDevice(err) {
+			return copyMove(src, dest, progress)
+		}
+		return err
+	}
+	if err := relinkWorktrees(src, dest); err != nil {
+		if rbErr := os.Rename(dest, src); rbErr != nil {
+			return fmt.Errorf("update worktrees: %w (rollback failed, project left at %s: %v)", err, dest, rbErr)
+		}
+		return fmt.Errorf("update worktrees: %w", err)
+	}
+	return nil
+}
+
+// moveLink moves the symlink src to dest, recreating it when dest is on
+// another filesystem.
+func moveLink(src, dest string) error {
+	err := os.Rename(src, dest)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Symlink(target, dest); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// git runs a git command and folds its stderr into the returned error.
+func git(dir string, args ...string) error {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
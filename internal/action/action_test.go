@@ -0,0 +1,280 @@
+package action
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGraduate(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "tries", "2024-01-15-redis")
+	dest := filepath.Join(tmpDir, "redis")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Graduate(src, dest, src, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "main.go")); err != nil {
+		t.Errorf("expected project moved to destination: %v", err)
+	}
+	target, err := os.Readlink(src)
+	if err != nil {
+		t.Fatalf("expected symlink at source: %v", err)
+	}
+	if target != dest {
+		t.Errorf("expected symlink to %s, got %s", dest, target)
+	}
+}
+
+func TestGraduate_DestinationExists(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	dest := filepath.Join(tmpDir, "dest")
+	for _, dir := range []string{src, dest} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := Graduate(src, dest, src, nil)
+	if err == nil {
+		t.Fatal("expected error when destination exists")
+	}
+	if !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("error should mention existing destination: %v", err)
+	}
+	if info, err := os.Lstat(src); err != nil || !info.IsDir() {
+		t.Error("source should be untouched")
+	}
+}
+
+func TestGraduate_RollbackOnSymlinkFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	dest := filepath.Join(tmpDir, "dest")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Symlink parent does not exist, so creating the link fails after the move.
+	link := filepath.Join(tmpDir, "missing", "link")
+	if err := Graduate(src, dest, link, nil); err == nil {
+		t.Fatal("expected error when symlink cannot be created")
+	}
+
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		t.Error("source should be restored after rollback")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("destination should not exist after rollback")
+	}
+}
+
+func TestDemote(t *testing.T) {
+	tmpDir := t.TempDir()
+	link := filepath.Join(tmpDir, "tries", "2024-01-15-redis")
+	project := filepath.Join(tmpDir, "redis")
+	if err := os.MkdirAll(link, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Graduate(link, project, link, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Demote(project, link, link); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || !info.IsDir() {
+		t.Errorf("expected a directory back in the tries root: %v", err)
+	}
+	if _, err := os.Lstat(project); !os.IsNotExist(err) {
+		t.Error("project should have moved")
+	}
+}
+
+func TestDemote_DestinationExists(t *testing.T) {
+	tmpDir := t.TempDir()
+	project := filepath.Join(tmpDir, "redis")
+	link := filepath.Join(tmpDir, "tries", "2024-01-15-redis")
+	dest := filepath.Join(tmpDir, "tries", "2024-01-16-redis")
+	for _, dir := range []string{project, dest} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(project, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Demote(project, dest, link); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected destination error, got %v", err)
+	}
+	if target, err := os.Readlink(link); err != nil || target != project {
+		t.Errorf("symlink should be restored, got %q (%v)", target, err)
+	}
+}
+
+func TestRename(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "2024-01-15-old")
+	dest := filepath.Join(tmpDir, "2024-01-15-new")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Rename(src, dest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dest); err != nil {
+		t.Errorf("expected renamed directory: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("old directory should be gone")
+	}
+}
+
+func TestRename_DestinationExists(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "a")
+	dest := filepath.Join(tmpDir, "b")
+	for _, dir := range []string{src, dest} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Rename(src, dest); err == nil {
+		t.Error("expected error when destination exists")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, "2024-01-15-redis")
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Delete(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("directory should be deleted")
+	}
+}
+
+func TestWorktreeLifecycle(t *testing.T) {
+	// Resolve symlinks (macOS /var -> /private/var) so paths match git's output
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := initRepo(t, filepath.Join(tmpDir, "repo"))
+	wt := filepath.Join(tmpDir, "tries", "2024-01-15-feature")
+	if err := os.MkdirAll(filepath.Dir(wt), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "worktree", "add", "--detach", wt)
+
+	if !IsWorktree(wt) {
+		t.Fatal("expected worktree to be detected")
+	}
+	if IsWorktree(repo) {
+		t.Error("main repository should not be detected as worktree")
+	}
+
+	renamed := filepath.Join(tmpDir, "tries", "2024-01-15-renamed")
+	if err := Rename(wt, renamed); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(runGit(t, repo, "worktree", "list"), renamed) {
+		t.Error("git should track the renamed worktree")
+	}
+
+	// Graduate and demote keep git's bookkeeping too
+	project := filepath.Join(tmpDir, "feature")
+	if err := Graduate(renamed, project, renamed, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Demote(project, renamed, renamed); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(runGit(t, repo, "worktree", "list"), renamed) {
+		t.Error("git should track the demoted worktree")
+	}
+
+	if err := Delete(renamed); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(runGit(t, repo, "worktree", "list"), renamed) {
+		t.Error("git should forget the deleted worktree")
+	}
+}
+
+func TestDelete_SymlinkToWorktree(t *testing.T) {
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := initRepo(t, filepath.Join(tmpDir, "repo"))
+	project := filepath.Join(tmpDir, "feature")
+	runGit(t, repo, "worktree", "add", "--detach", project)
+	link := filepath.Join(tmpDir, "tries", "2024-01-15-feature")
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(project, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if IsWorktree(link) {
+		t.Error("a symlink to a worktree should not be detected as one")
+	}
+	moved := filepath.Join(tmpDir, "tries", "2024-01-15-moved")
+	if err := Rename(link, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(moved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(moved); !os.IsNotExist(err) {
+		t.Error("link should be deleted")
+	}
+	if !IsWorktree(project) {
+		t.Error("the worktree the link pointed to should be left alone")
+	}
+	if !strings.Contains(runGit(t, repo, "worktree", "list"), project) {
+		t.Error("git should still track the worktree at its own path")
+	}
+}
+
+func initRepo(t *testing.T, dir string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "-c", "user.name=try", "-c", "user.email=try@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
@@ -0,0 +1,51 @@
+package action
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// InitialCommitMessage is the message of the commit InitRepo makes.
+const InitialCommitMessage = "Initial commit"
+
+// InitRepo makes dir a git repository holding a commit of its current
+// files, unless it is one already. It reports whether it created one.
+func InitRepo(dir string) (bool, error) {
+	if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+		return false, nil
+	}
+	if err := git(dir, "init", "-q"); err != nil {
+		return false, fmt.Errorf("git init: %w", err)
+	}
+	if err := git(dir, "add", "-A"); err != nil {
+		return true, fmt.Errorf("git add: %w", err)
+	}
+	if err := git(dir, "commit", "-q", "--allow-empty", "-m", InitialCommitMessage); err != nil {
+		return true, fmt.Errorf("initial commit: %w", err)
+	}
+	return true, nil
+}
+
+// AddRemote creates a bare repository at remote unless one exists, adds
+// it as origin of the repository at dir and pushes the current branch.
+func AddRemote(dir, remote string) error {
+	if err := git(dir, "remote", "get-url", "origin"); err == nil {
+		return fmt.Errorf("origin is already set")
+	}
+	if _, err := os.Stat(remote); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(remote), 0755); err != nil {
+			return err
+		}
+		if err := git("", "init", "-q", "--bare", remote); err != nil {
+			return fmt.Errorf("create %s: %w", remote, err)
+		}
+	}
+	if err := git(dir, "remote", "add", "origin", remote); err != nil {
+		return fmt.Errorf("add remote: %w", err)
+	}
+	if err := git(dir, "push", "-q", "-u", "origin", "HEAD"); err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package action
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitRepoAndAddRemote(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "try")
+	t.Setenv("GIT_AUTHOR_EMAIL", "try@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "try")
+	t.Setenv("GIT_COMMITTER_EMAIL", "try@example.com")
+
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, "redis")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := InitRepo(dir)
+	if err != nil || !created {
+		t.Fatalf("InitRepo() = %v, %v", created, err)
+	}
+	if files := runGit(t, dir, "ls-files"); !strings.Contains(files, "main.go") {
+		t.Errorf("expected main.go committed, got %q", files)
+	}
+	if created, err := InitRepo(dir); err != nil || created {
+		t.Errorf("second InitRepo() = %v, %v; expected a no-op", created, err)
+	}
+
+	remote := filepath.Join(tmpDir, "git", "redis.git")
+	if err := AddRemote(dir, remote); err != nil {
+		t.Fatal(err)
+	}
+	if log := runGit(t, remote, "log", "--oneline"); !strings.Contains(log, InitialCommitMessage) {
+		t.Errorf("expected the commit pushed to the bare remote, got %q", log)
+	}
+	if err := AddRemote(dir, remote); err == nil {
+		t.Error("expected an error when origin is already set")
+	}
+}
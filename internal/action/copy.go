@@ -0,0 +1,344 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Progress reports bytes copied so far out of total during a move across
+// filesystems. Returning an error cancels the move, leaving src intact.
+type Progress func(done, total int64) error
+
+// partialSuffix marks a copy in progress next to its destination.
+const partialSuffix = ".try-partial"
+
+// treeStats counts what a copy must reproduce.
+type treeStats struct {
+	Files    int   // Regular files
+	Symlinks int   // Symlinks, copied as links
+	Bytes    int64 // Size of regular files
+}
+
+// CrossDevice reports whether src and the directory destDir are on
+// different filesystems, where a move has to copy.
+func CrossDevice(src, destDir string) bool {
+	same, err := sameDevice(src, destDir)
+	return err == nil && !same
+}
+
+// isCrossDevice reports whether err is a rename across filesystems.
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
+
+// copyMove moves src to dest across filesystems: it copies into a
+// sibling of dest, checks that every file and byte arrived, renames the
+// copy into place and only then removes src. A failed or cancelled copy
+// removes the partial copy and leaves src untouched. Worktrees, and the
+// linked worktrees of a repository, have their gitdir pointers updated.
+func copyMove(src, dest string, progress Progress) error {
+	want, err := statTree(src)
+	if err != nil {
+		return fmt.Errorf("scan %s: %w", src, err)
+	}
+
+	tmp := dest + partialSuffix
+	if err := removeTree(tmp); err != nil { // Left over from an interrupted move
+		return err
+	}
+	if err := copyTree(src, tmp, want.Bytes, progress); err != nil {
+		_ = removeTree(tmp)
+		return fmt.Errorf("copy: %w", err)
+	}
+	got, err := statTree(tmp)
+	if err != nil || got != want {
+		_ = removeTree(tmp)
+		if err == nil {
+			err = fmt.Errorf("copied %d files, %d symlinks, %d bytes; expected %d, %d, %d",
+				got.Files, got.Symlinks, got.Bytes, want.Files, want.Symlinks, want.Bytes)
+		}
+		return fmt.Errorf("verify copy: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = removeTree(tmp)
+		return err
+	}
+
+	if IsWorktree(dest) {
+		if err := relinkWorktree(src, dest); err != nil {
+			_ = removeTree(dest)
+			return fmt.Errorf("update worktree: %w", err)
+		}
+	} else if err := relinkWorktrees(src, dest); err != nil {
+		_ = removeTree(dest)
+		return fmt.Errorf("update worktrees: %w", err)
+	}
+	if err := removeTree(src); err != nil {
+		return fmt.Errorf("copied to %s but removing the original failed: %w", dest, err)
+	}
+	return nil
+}
+
+// removeTree removes root like os.RemoveAll, first making its
+// directories writable so read-only ones can be emptied.
+func removeTree(root string) error {
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			if info, err := d.Info(); err == nil && info.Mode().Perm()&0700 != 0700 {
+				_ = os.Chmod(path, info.Mode().Perm()|0700)
+			}
+		}
+		return nil
+	})
+	return os.RemoveAll(root)
+}
+
+// statTree counts the regular files, symlinks and bytes under root.
+func statTree(root string) (treeStats, error) {
+	var s treeStats
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			s.Symlinks++
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			s.Files++
+			s.Bytes += info.Size()
+		}
+		return nil
+	})
+	return s, err
+}
+
+// copyTree copies src to dest, keeping modes, times and symlinks.
+// Sockets, devices and other special files are skipped.
+func copyTree(src, dest string, total int64, progress Progress) error {
+	var done int64
+	report := func(n int64) error {
+		done += n
+		if progress == nil {
+			return nil
+		}
+		return progress(done, total)
+	}
+
+	type dirInfo struct {
+		path    string
+		mode    fs.FileMode
+		modTime time.Time
+	}
+	var dirs []dirInfo
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()|0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirInfo{target, info.Mode().Perm(), info.ModTime()})
+			return nil
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			if err := copyFile(path, target, info, report); err != nil {
+				return err
+			}
+			return report(0) // Lets empty files notice cancellation too
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Restore directory modes and times last, as writing files changes them
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Chmod(dirs[i].path, dirs[i].mode)
+		_ = os.Chtimes(dirs[i].path, dirs[i].modTime, dirs[i].modTime)
+	}
+	return nil
+}
+
+func copyFile(src, dest string, info fs.FileInfo, report func(int64) error) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(&progressWriter{w: out, report: report}, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dest, info.ModTime(), info.ModTime())
+}
+
+// progressWriter reports every write, so large files show progress and
+// can be cancelled midway.
+type progressWriter struct {
+	w      io.Writer
+	report func(int64) error
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if err != nil {
+		return n, err
+	}
+	return n, p.report(int64(n))
+}
+
+// rewrite is a file to replace with new contents.
+type rewrite struct {
+	path string
+	data []byte
+}
+
+// applyRewrites writes every file in order. If one can't be written, all
+// of them get their old contents back, so both ends of a worktree link
+// always agree.
+func applyRewrites(rewrites []rewrite) error {
+	var done []rewrite
+	for _, r := range rewrites {
+		old, err := os.ReadFile(r.path)
+		if err == nil {
+			done = append(done, rewrite{r.path, old})
+			err = os.WriteFile(r.path, r.data, 0644)
+		}
+		if err != nil {
+			for i := len(done) - 1; i >= 0; i-- {
+				_ = os.WriteFile(done[i].path, done[i].data, 0644)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// relinkWorktree points a worktree copied from src to dest and its
+// repository's admin directory at each other again, as
+// `git worktree repair` would. Both files are read before either is
+// written, and neither is left changed if the other can't be.
+func relinkWorktree(src, dest string) error {
+	dotGit := filepath.Join(dest, ".git")
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return err
+	}
+	admin, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return fmt.Errorf("unexpected %s", dotGit)
+	}
+	admin = strings.TrimSpace(admin)
+	if !filepath.IsAbs(admin) {
+		// Relative to where the worktree used to be
+		admin = filepath.Join(src, admin)
+	}
+	return applyRewrites([]rewrite{
+		{dotGit, []byte("gitdir: " + admin + "\n")},
+		{filepath.Join(admin, "gitdir"), []byte(dotGit + "\n")},
+	})
+}
+
+// relinkWorktrees points the linked worktrees of a repository moved from
+// src to dest at its admin directories under dest/.git/worktrees, as
+// `git worktree repair` run in dest would. Worktrees inside the
+// repository moved with it. Only worktrees still pointing at src are
+// touched. Every link is worked out before anything is written; if one
+// can't be updated, both ends of every link are restored.
+func relinkWorktrees(src, dest string) error {
+	admins, err := filepath.Glob(filepath.Join(dest, ".git", "worktrees", "*"))
+	if err != nil || len(admins) == 0 {
+		return err
+	}
+	// git records resolved paths; src itself may be gone by now
+	srcs := []string{src}
+	if parent, err := filepath.EvalSymlinks(filepath.Dir(src)); err == nil {
+		srcs = append(srcs, filepath.Join(parent, filepath.Base(src)))
+	}
+	moved := func(path string) (string, bool) {
+		for _, s := range srcs {
+			rel, err := filepath.Rel(s, path)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return filepath.Join(dest, rel), true
+			}
+		}
+		return path, false
+	}
+
+	var rewrites []rewrite
+	for _, admin := range admins {
+		data, err := os.ReadFile(filepath.Join(admin, "gitdir"))
+		if err != nil {
+			continue // Not a worktree admin directory
+		}
+		rel, err := filepath.Rel(dest, admin)
+		if err != nil {
+			return err
+		}
+		oldAdmin := filepath.Join(src, rel) // Relative gitdirs start here
+		dotGit := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(dotGit) {
+			dotGit = filepath.Join(oldAdmin, dotGit)
+		}
+		dotGit, _ = moved(filepath.Clean(dotGit))
+
+		old, err := os.ReadFile(dotGit)
+		if err != nil {
+			continue // Stale; git worktree prune cleans it up
+		}
+		pointer, ok := strings.CutPrefix(strings.TrimSpace(string(old)), "gitdir:")
+		if !ok {
+			continue
+		}
+		pointer = strings.TrimSpace(pointer)
+		if !filepath.IsAbs(pointer) {
+			pointer = filepath.Join(filepath.Dir(dotGit), pointer)
+		}
+		if _, ok := moved(filepath.Clean(pointer)); !ok {
+			continue // Belongs to another repository
+		}
+
+		rewrites = append(rewrites,
+			rewrite{dotGit, []byte("gitdir: " + admin + "\n")},
+			rewrite{filepath.Join(admin, "gitdir"), []byte(dotGit + "\n")},
+		)
+	}
+	return applyRewrites(rewrites)
+}
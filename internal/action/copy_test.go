@@ -0,0 +1,246 @@
+package action
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyMove(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "tries", "2024-01-15-redis")
+	dest := filepath.Join(tmpDir, "redis")
+	files := map[string]string{
+		"main.go":          "package main",
+		"empty":            "",
+		"cmd/tool/x.go":    "package tool",
+		"scripts/run.sh":   "#!/bin/sh",
+		"data/big.bin":     strings.Repeat("x", 100_000),
+		".git/HEAD":        "ref: refs/heads/main",
+		"docs/nested/a.md": "# a",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(src, "scripts/run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("main.go", filepath.Join(src, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	var last, total int64
+	progress := func(done, all int64) error {
+		last, total = done, all
+		return nil
+	}
+	if err := copyMove(src, dest, progress); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dest, name))
+		if err != nil || string(data) != content {
+			t.Errorf("%s: got %q (%v)", name, data, err)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dest, "scripts/run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("expected mode kept, got %v (%v)", info.Mode(), err)
+	}
+	if target, err := os.Readlink(filepath.Join(dest, "link.go")); err != nil || target != "main.go" {
+		t.Errorf("expected symlink kept, got %q (%v)", target, err)
+	}
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Error("expected source removed")
+	}
+	if _, err := os.Lstat(dest + partialSuffix); !os.IsNotExist(err) {
+		t.Error("expected no partial copy left")
+	}
+	if last != total || total < 100_000 {
+		t.Errorf("expected progress to reach the total, got %d/%d", last, total)
+	}
+}
+
+func TestCopyMove_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	dest := filepath.Join(tmpDir, "dest")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancelled := errors.New("cancelled")
+	err := copyMove(src, dest, func(done, total int64) error {
+		if done >= 2 {
+			return cancelled
+		}
+		return nil
+	})
+	if !errors.Is(err, cancelled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(src, "c")); err != nil {
+		t.Error("source should be untouched")
+	}
+	for _, p := range []string{dest, dest + partialSuffix} {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %s removed", p)
+		}
+	}
+}
+
+func TestCopyMove_ReadOnlyDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	dest := filepath.Join(tmpDir, "dest")
+	for _, dir := range []string{src, dest + partialSuffix} {
+		locked := filepath.Join(dir, "vendor")
+		if err := os.MkdirAll(locked, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(locked, "lib.go"), []byte("package lib"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(locked, 0555); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { _ = os.Chmod(filepath.Join(dest, "vendor"), 0755) })
+
+	// The leftover partial copy and the source are both read-only inside
+	if err := copyMove(src, dest, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Errorf("expected source removed, got %v", err)
+	}
+	if _, err := os.Lstat(dest + partialSuffix); !os.IsNotExist(err) {
+		t.Error("expected no partial copy left")
+	}
+	info, err := os.Stat(filepath.Join(dest, "vendor"))
+	if err != nil || info.Mode().Perm() != 0555 {
+		t.Errorf("expected mode kept, got %v (%v)", info, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "vendor", "lib.go")); err != nil || string(data) != "package lib" {
+		t.Errorf("lib.go: got %q (%v)", data, err)
+	}
+}
+
+func TestCopyMove_Worktree(t *testing.T) {
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := initRepo(t, filepath.Join(tmpDir, "repo"))
+	wt := filepath.Join(tmpDir, "tries", "2024-01-15-feature")
+	if err := os.MkdirAll(filepath.Dir(wt), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "worktree", "add", "--detach", wt)
+
+	dest := filepath.Join(tmpDir, "projects", "feature")
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyMove(wt, dest, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, dest, "status") // Fails if the worktree lost its repository
+	if list := runGit(t, repo, "worktree", "list"); !strings.Contains(list, dest) || strings.Contains(list, wt) {
+		t.Errorf("expected git to track the worktree at %s, got:\n%s", dest, list)
+	}
+	if out := runGit(t, repo, "worktree", "prune", "--dry-run", "-v"); strings.TrimSpace(out) != "" {
+		t.Errorf("worktree should not be prunable: %s", out)
+	}
+}
+
+func TestCopyMove_RepositoryWithWorktrees(t *testing.T) {
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := initRepo(t, filepath.Join(tmpDir, "tries", "2024-01-15-app"))
+	outside := filepath.Join(tmpDir, "tries", "2024-01-16-app-feature")
+	runGit(t, repo, "worktree", "add", "--detach", outside)
+	runGit(t, repo, "worktree", "add", "--detach", filepath.Join(repo, "nested"))
+
+	dest := filepath.Join(tmpDir, "projects", "app")
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyMove(repo, dest, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	nested := filepath.Join(dest, "nested")
+	for _, dir := range []string{dest, outside, nested} {
+		runGit(t, dir, "status") // Fails if the worktree lost its repository
+	}
+	list := runGit(t, dest, "worktree", "list")
+	for _, dir := range []string{dest, outside, nested} {
+		if !strings.Contains(list, dir+" ") {
+			t.Errorf("expected git to track %s, got:\n%s", dir, list)
+		}
+	}
+	if out := runGit(t, dest, "worktree", "prune", "--dry-run", "-v"); strings.TrimSpace(out) != "" {
+		t.Errorf("no worktree should be prunable: %s", out)
+	}
+}
+
+func TestMove_RepositoryWithWorktrees(t *testing.T) {
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := initRepo(t, filepath.Join(tmpDir, "tries", "2024-01-15-app"))
+	outside := filepath.Join(tmpDir, "tries", "2024-01-16-app-feature")
+	runGit(t, repo, "worktree", "add", "--detach", outside)
+
+	dest := filepath.Join(tmpDir, "app")
+	if err := move(repo, dest, nil); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, outside, "status")
+	if list := runGit(t, dest, "worktree", "list"); !strings.Contains(list, outside) {
+		t.Errorf("expected git to track %s, got:\n%s", outside, list)
+	}
+}
+
+func TestApplyRewrites_Rollback(t *testing.T) {
+	dir := t.TempDir()
+	dotGit := filepath.Join(dir, ".git")
+	admin := filepath.Join(dir, "gitdir")
+	for _, path := range []string{dotGit, admin} {
+		if err := os.WriteFile(path, []byte("old "+filepath.Base(path)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := applyRewrites([]rewrite{
+		{dotGit, []byte("new")},
+		{admin, []byte("new")},
+		{filepath.Join(dir, "missing", "gitdir"), []byte("new")},
+	})
+	if err == nil {
+		t.Fatal("expected error for the missing file")
+	}
+	for _, path := range []string{dotGit, admin} {
+		if data, _ := os.ReadFile(path); string(data) != "old "+filepath.Base(path) {
+			t.Errorf("%s not restored, got %q", filepath.Base(path), data)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package action
+
+// sameDevice assumes one filesystem; a failing rename still falls back
+// to copying.
+func sameDevice(a, b string) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package action
+
+import (
+	"os"
+	"syscall"
+)
+
+// sameDevice reports whether a and b are on the same filesystem.
+func sameDevice(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	as, aok := ai.Sys().(*syscall.Stat_t)
+	bs, bok := bi.Sys().(*syscall.Stat_t)
+	if !aok || !bok {
+		return true, nil
+	}
+	return as.Dev == bs.Dev, nil
+}
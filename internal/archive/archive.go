@@ -0,0 +1,362 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xpzouying/try/internal/meta"
+)
+
+// Ext is the file extension of archives.
+const Ext = ".tar.gz"
+
+// metaMember is the first tar member of every archive; the experiment's
+// files follow under "<name>/".
+const metaMember = "try-meta.json"
+
+// Archive is an archived experiment.
+type Archive struct {
+	Name       string     // Original directory name
+	Path       string     // Archive file
+	Size       int64      // Compressed size in bytes
+	ArchivedAt time.Time  // When the archive was written
+	Meta       *meta.Meta // Metadata recorded at archive time, nil if none
+}
+
+// Dir returns the archive directory of a tries root.
+func Dir(root string) string {
+	return filepath.Join(root, meta.Dir, "archive")
+}
+
+// Root returns the tries root that the archive file p belongs to.
+func Root(p string) string {
+	return filepath.Dir(filepath.Dir(filepath.Dir(p)))
+}
+
+// PathFor returns the archive file for experiment name under root.
+func PathFor(root, name string) string {
+	return filepath.Join(Dir(root), name+Ext)
+}
+
+// Create packs the directory src and its metadata into a gzipped tarball
+// at dst. Regular files, directories and symlinks are kept with their
+// modes and times; anything else is skipped. dst must not exist.
+func Create(src, dst string, m *meta.Meta) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("archive already exists: %s", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	tmp := dst + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = write(f, src, m)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+func write(w io.Writer, src string, m *meta.Meta) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     metaMember,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	name := filepath.Base(src)
+	err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		case !info.Mode().IsRegular() && !info.IsDir():
+			return nil // Sockets, devices and pipes don't survive archiving
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(name, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("pack %s: %w", name, err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Extract unpacks the archive at src into the directory dest, which must
+// not exist, and returns the recorded metadata. It unpacks into a
+// temporary sibling first, so a failure leaves nothing behind. Members
+// that would escape dest are rejected.
+func Extract(src, dest string) (*meta.Meta, error) {
+	if _, err := os.Lstat(dest); err == nil {
+		return nil, fmt.Errorf("destination already exists: %s", dest)
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(src), err)
+	}
+	tr := tar.NewReader(gz)
+
+	m, err := readMeta(tr)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(src), err)
+	}
+
+	tmp := dest + ".restoring"
+	if err := os.RemoveAll(tmp); err != nil {
+		return nil, err
+	}
+	if err := unpack(tr, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return nil, fmt.Errorf("unpack %s: %w", filepath.Base(src), err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.RemoveAll(tmp)
+		return nil, err
+	}
+	return m, nil
+}
+
+// unpack writes the experiment's members into dir, then restores
+// directory modes and times: a read-only directory can't be filled, and
+// writing a directory's contents changes its time.
+func unpack(tr *tar.Reader, dir string) error {
+	type dirInfo struct {
+		path string
+		mode os.FileMode
+		time time.Time
+	}
+	var dirs []dirInfo
+	var links []string // Never written through, so a link can't redirect a later member
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := memberPath(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if strings.HasPrefix(target, link+string(filepath.Separator)) {
+				return fmt.Errorf("member %s is inside symlink %s", hdr.Name, filepath.Base(link))
+			}
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirInfo{target, hdr.FileInfo().Mode().Perm(), hdr.ModTime})
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, hdr.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+			if err := os.Chtimes(target, hdr.ModTime, hdr.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+			links = append(links, target)
+		default:
+			return fmt.Errorf("unsupported member type for %s", hdr.Name)
+		}
+	}
+
+	// Deepest first, so fixing a child doesn't disturb its parent
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i].path) > len(dirs[j].path) })
+	for _, d := range dirs {
+		if err := os.Chmod(d.path, d.mode); err != nil {
+			return err
+		}
+		if err := os.Chtimes(d.path, d.time, d.time); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memberPath maps "<name>/rest" to dir/rest, refusing anything that
+// would land outside dir.
+func memberPath(dir, name string) (string, error) {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("unsafe member path: %s", name)
+	}
+	_, rest, _ := strings.Cut(clean, "/")
+	return filepath.Join(dir, filepath.FromSlash(rest)), nil
+}
+
+// readMeta reads the leading metadata member.
+func readMeta(tr *tar.Reader) (*meta.Meta, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if hdr.Name != metaMember {
+		return nil, errors.New("not a try archive")
+	}
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+	var m *meta.Meta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse metadata: %w", err)
+	}
+	return m, nil
+}
+
+// Open describes the archive at p, reading only its metadata.
+func Open(p string) (*Archive, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(p), err)
+	}
+	m, err := readMeta(tar.NewReader(gz))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(p), err)
+	}
+	return &Archive{
+		Name:       strings.TrimSuffix(filepath.Base(p), Ext),
+		Path:       p,
+		Size:       info.Size(),
+		ArchivedAt: info.ModTime(),
+		Meta:       m,
+	}, nil
+}
+
+// List returns the archives of a tries root, newest first. Unreadable
+// archives are skipped.
+func List(root string) ([]*Archive, error) {
+	files, err := os.ReadDir(Dir(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var result []*Archive
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), Ext) {
+			continue
+		}
+		a, err := Open(filepath.Join(Dir(root), f.Name()))
+		if err != nil {
+			continue
+		}
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ArchivedAt.After(result[j].ArchivedAt)
+	})
+	return result, nil
+}
@@ -0,0 +1,179 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xpzouying/try/internal/meta"
+)
+
+func TestCreateExtract(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "2024-01-15-redis")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "run.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("sub/run.sh", filepath.Join(src, "run")); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(src, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &meta.Meta{Description: "cache test", Tags: []string{"db"}}
+	dst := PathFor(root, "2024-01-15-redis")
+	if err := Create(src, dst, m); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := Create(src, dst, m); err == nil {
+		t.Error("expected error when the archive exists")
+	}
+
+	archives, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 1 || archives[0].Name != "2024-01-15-redis" || archives[0].Meta.Description != "cache test" {
+		t.Fatalf("List() = %+v", archives)
+	}
+
+	if _, err := Extract(dst, src); err == nil {
+		t.Error("expected error when the destination exists")
+	}
+
+	dest := filepath.Join(root, "restored")
+	got, err := Extract(dst, dest)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if got == nil || got.Description != "cache test" || len(got.Tags) != 1 {
+		t.Errorf("Extract() meta = %+v", got)
+	}
+
+	info, err := os.Stat(filepath.Join(dest, "sub", "run.sh"))
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh not restored with its mode: %v, %v", info, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dest, "run")); err != nil || link != "sub/run.sh" {
+		t.Errorf("symlink not restored: %q, %v", link, err)
+	}
+	if info, err := os.Stat(dest); err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("directory time not restored: %v", info.ModTime())
+	}
+}
+
+func TestExtract_ReadOnlyDir(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "2024-01-15-redis")
+	dest := filepath.Join(root, "restored")
+	if err := os.MkdirAll(filepath.Join(src, "docs", "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "docs", "api", "index.md"), []byte("# API"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{filepath.Join(src, "docs", "api"), filepath.Join(src, "docs")} {
+		if err := os.Chmod(dir, 0555); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, top := range []string{src, dest} {
+			_ = filepath.WalkDir(top, func(path string, d os.DirEntry, err error) error {
+				if err == nil && d.IsDir() {
+					_ = os.Chmod(path, 0755)
+				}
+				return nil
+			})
+		}
+	})
+
+	dst := PathFor(root, "2024-01-15-redis")
+	if err := Create(src, dst, nil); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := Extract(dst, dest); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "docs", "api", "index.md")); err != nil || string(data) != "# API" {
+		t.Errorf("index.md not restored: %q, %v", data, err)
+	}
+	for _, dir := range []string{"docs", filepath.Join("docs", "api")} {
+		if info, err := os.Stat(filepath.Join(dest, dir)); err != nil || info.Mode().Perm() != 0555 {
+			t.Errorf("%s not restored read-only: %v, %v", dir, info, err)
+		}
+	}
+}
+
+func TestCreate_NoMeta(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "exp")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	dst := PathFor(root, "exp")
+	if err := Create(src, dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Extract(dst, filepath.Join(root, "back"))
+	if err != nil || m != nil {
+		t.Errorf("Extract() = %+v, %v; expected no metadata", m, err)
+	}
+}
+
+// writeTar builds an archive from raw headers to test hostile input.
+func writeTar(t *testing.T, headers ...*tar.Header) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "evil"+Ext)
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	headers = append([]*tar.Header{{Name: metaMember, Mode: 0644, Size: 4, Typeflag: tar.TypeReg}}, headers...)
+	for i, hdr := range headers {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			_, _ = tw.Write([]byte("null"))
+		} else if hdr.Typeflag == tar.TypeReg {
+			_, _ = tw.Write(make([]byte, hdr.Size))
+		}
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	_ = f.Close()
+	return p
+}
+
+func TestExtract_Unsafe(t *testing.T) {
+	tests := map[string][]*tar.Header{
+		"parent":   {{Name: "exp/../../escape", Mode: 0644, Typeflag: tar.TypeReg}},
+		"absolute": {{Name: "/tmp/escape", Mode: 0644, Typeflag: tar.TypeReg}},
+		"through symlink": {
+			{Name: "exp/link", Linkname: "/tmp", Typeflag: tar.TypeSymlink},
+			{Name: "exp/link/escape", Mode: 0644, Typeflag: tar.TypeReg},
+		},
+	}
+	for name, headers := range tests {
+		t.Run(name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "exp")
+			if _, err := Extract(writeTar(t, headers...), dest); err == nil {
+				t.Error("expected error")
+			}
+			if _, err := os.Lstat(dest); !os.IsNotExist(err) {
+				t.Error("failed extract left the destination behind")
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package clean
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/xpzouying/try/internal/du"
+)
+
+// Match is a regenerable directory found inside an experiment.
+type Match struct {
+	Path string
+	Size int64
+}
+
+// CheckPatterns reports the first malformed glob in patterns.
+func CheckPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Find returns the directories under dir whose name matches one of the
+// glob patterns, with their sizes. Matches are not searched further, .git
+// is never entered and symlinks are never followed.
+func Find(ctx context.Context, dir string, patterns []string) ([]Match, error) {
+	var matches []Match
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // Skip what we can't read
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() || path == dir {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		for _, p := range patterns {
+			if ok, _ := filepath.Match(p, d.Name()); ok {
+				size, err := du.Size(ctx, path)
+				if err != nil {
+					return err
+				}
+				matches = append(matches, Match{Path: path, Size: size})
+				return fs.SkipDir
+			}
+		}
+		return nil
+	})
+	return matches, err
+}
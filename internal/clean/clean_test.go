@@ -0,0 +1,59 @@
+package clean
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.go"), 10)
+	writeFile(t, filepath.Join(dir, "node_modules", "pkg", "node_modules", "dep.js"), 100)
+	writeFile(t, filepath.Join(dir, "py", "__pycache__", "m.pyc"), 20)
+	writeFile(t, filepath.Join(dir, ".git", "target", "x"), 5)
+	writeFile(t, filepath.Join(dir, "dist"), 7) // A file, not a directory
+
+	// A symlinked match is left alone
+	if err := os.Symlink(filepath.Join(dir, "node_modules"), filepath.Join(dir, "py", "node_modules")); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := Find(context.Background(), dir, []string{"node_modules", "__py*", "target", "dist"})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	expected := map[string]int64{
+		filepath.Join(dir, "node_modules"):      100,
+		filepath.Join(dir, "py", "__pycache__"): 20,
+	}
+	if len(matches) != len(expected) {
+		t.Fatalf("Find() = %+v, expected %d matches", matches, len(expected))
+	}
+	for _, m := range matches {
+		if size, ok := expected[m.Path]; !ok || size != m.Size {
+			t.Errorf("unexpected match %+v", m)
+		}
+	}
+}
+
+func TestCheckPatterns(t *testing.T) {
+	if err := CheckPatterns([]string{"node_modules", "*.egg-info"}); err != nil {
+		t.Errorf("CheckPatterns() error = %v", err)
+	}
+	if err := CheckPatterns([]string{"[oops"}); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
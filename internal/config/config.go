@@ -0,0 +1,557 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the value type of a config key.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindNumber
+	KindBool
+	KindList
+)
+
+// Source tells where a resolved value came from.
+// Precedence, highest first: flag, env, file, default.
+type Source string
+
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceDefault Source = "default"
+)
+
+// Key describes a supported config key.
+type Key struct {
+	Name    string // Dotted name, e.g. "core.path" ([core] path = ...)
+	Kind    Kind
+	Default string
+	Env     string // Environment variable that overrides the file, if any
+	Doc     string
+
+	env   func() (string, bool) // Custom env lookup, used instead of Env
+	check func(string) error    // Extra validation beyond the kind
+}
+
+// Keys lists every supported key in display order.
+var Keys = []Key{
+	{Name: "core.path", Default: "~/tries", Env: "TRY_PATH", Doc: "root directory for experiments"},
+	{Name: "core.projects", Env: "TRY_PROJECTS", Doc: "graduate destination (default: parent of core.path)"},
+	{Name: "core.date_format", Default: "2006-01-02", Env: "TRY_DATE_FORMAT", Doc: "Go time layout for new names' date prefix", check: checkDateFormat},
+	{Name: "core.profile", Env: "TRY_PROFILE", Doc: "active profile: a name, comma-separated names, or all"},
+
+	{Name: "rank.match", Kind: KindNumber, Default: "1", env: weightEnv("match"), Doc: "weight of fuzzy match quality"},
+	{Name: "rank.recency", Kind: KindNumber, Default: "0.5", env: weightEnv("recency"), Doc: "weight of modification recency"},
+	{Name: "rank.frecency", Kind: KindNumber, Default: "0.5", env: weightEnv("frecency"), Doc: "weight of visit frecency"},
+	{Name: "rank.pin", Kind: KindNumber, Default: "1", env: weightEnv("pin"), Doc: "weight of being pinned"},
+
+	{Name: "ui.theme", Default: "default", Env: "TRY_THEME", Doc: "selector colors: default or mono", check: oneOf("default", "mono")},
+	{Name: "ui.preview", Kind: KindBool, Default: "false", Doc: "open the selector with the preview pane shown"},
+	{Name: "ui.show_archived", Kind: KindBool, Default: "false", Doc: "list archived experiments, dimmed, in the selector (Enter restores)"},
+
+	{Name: "keys.new", Default: "ctrl+t", Doc: "create a new experiment from the query"},
+	{Name: "keys.graduate", Default: "ctrl+g", Doc: "graduate the selected experiment"},
+	{Name: "keys.delete", Default: "ctrl+d", Doc: "delete the selected experiment"},
+	{Name: "keys.rename", Default: "ctrl+r", Doc: "rename the selected experiment"},
+	{Name: "keys.describe", Default: "ctrl+e", Doc: "edit description and tags"},
+	{Name: "keys.preview", Default: "ctrl+o", Doc: "toggle the preview pane"},
+	{Name: "keys.sort", Default: "ctrl+s", Doc: "toggle sorting by disk usage"},
+	{Name: "keys.archive", Default: "ctrl+a", Doc: "archive the selected experiment"},
+
+	{Name: "hooks.post_create", Doc: "shell command run in a new experiment after cd"},
+	{Name: "hooks.post_clone", Doc: "shell command run in a cloned experiment after cd"},
+
+	{Name: "clone.depth", Kind: KindNumber, Default: "0", Doc: "git clone --depth (0 clones full history)", check: checkNonNegative},
+	{Name: "clone.args", Kind: KindList, Doc: "extra git clone arguments"},
+
+	{Name: "trash.expire_days", Kind: KindNumber, Default: "30", Doc: "days before deleted experiments are purged from .trash (0 keeps them)", check: checkNonNegative},
+
+	{Name: "graduate.git_init", Kind: KindBool, Default: "false", Doc: "git init and commit graduated projects that aren't repositories yet"},
+	{Name: "graduate.remote", Doc: "directory of bare repositories; graduate adds <dir>/<name>.git as origin and pushes"},
+
+	{Name: "clean.patterns", Kind: KindList, Default: "node_modules target .venv __pycache__ build dist .gradle", Doc: "directory names try clean removes (globs allowed)"},
+}
+
+// profileKeys are the keys of a [profile.<name>] table.
+var profileKeys = []Key{
+	{Name: "path", Doc: "root directory for the profile's experiments"},
+	{Name: "projects", Doc: "graduate destination (default: parent of path)"},
+}
+
+// Lookup returns the key with the given name, including
+// profile.<name>.path and profile.<name>.projects.
+func Lookup(name string) (Key, bool) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	if profile, key, ok := splitProfileKey(name); ok && validProfileName(profile) {
+		for _, k := range profileKeys {
+			if k.Name == key {
+				k.Name = name
+				return k, true
+			}
+		}
+	}
+	return Key{}, false
+}
+
+// splitProfileKey splits "profile.work.path" into "work" and "path".
+func splitProfileKey(name string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(name, "profile.")
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndexByte(rest, '.')
+	if i <= 0 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+func validProfileName(name string) bool {
+	if name == "" || name == "all" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// EnvName describes the environment override of the key, if any.
+func (k Key) EnvName() string {
+	if k.Env != "" {
+		return k.Env
+	}
+	if k.env != nil {
+		return weightsEnv
+	}
+	return ""
+}
+
+func (k Key) lookupEnv() (string, bool) {
+	if k.env != nil {
+		return k.env()
+	}
+	if k.Env == "" {
+		return "", false
+	}
+	v := os.Getenv(k.Env)
+	return v, v != ""
+}
+
+// validate checks value against the key's kind and constraints.
+func (k Key) validate(value string) error {
+	switch k.Kind {
+	case KindNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s: %q is not a number", k.Name, value)
+		}
+	case KindBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s: %q is not true or false", k.Name, value)
+		}
+	}
+	if k.check != nil {
+		if err := k.check(value); err != nil {
+			return fmt.Errorf("%s: %w", k.Name, err)
+		}
+	}
+	return nil
+}
+
+// Config holds the values read from the config file plus per-invocation
+// overrides. Environment variables are consulted on every lookup.
+type Config struct {
+	path      string
+	data      string
+	values    map[string]string
+	lists     map[string][]string
+	overrides map[string]string
+}
+
+// FilePath returns the config file location: $TRY_CONFIG, else
+// $XDG_CONFIG_HOME/try/config.toml, else ~/.config/try/config.toml.
+func FilePath() string {
+	if path := os.Getenv("TRY_CONFIG"); path != "" {
+		return path
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "try", "config.toml")
+}
+
+// Load reads the config file at path. A missing file is an empty config.
+func Load(path string) (*Config, error) {
+	c := newConfig(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	c.data = string(data)
+
+	values, lists, err := parseTOML(c.data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for name, value := range values {
+		k, ok := Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("parse %s: unknown key %s", path, name)
+		}
+		if k.Kind == KindList {
+			return nil, fmt.Errorf("parse %s: %s must be an array of strings", path, name)
+		}
+		if err := k.validate(value); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	for name := range lists {
+		k, ok := Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("parse %s: unknown key %s", path, name)
+		}
+		if k.Kind != KindList {
+			return nil, fmt.Errorf("parse %s: %s must not be an array", path, name)
+		}
+	}
+	c.values = values
+	c.lists = lists
+	for _, profile := range c.Profiles() {
+		if c.values["profile."+profile+".path"] == "" {
+			return nil, fmt.Errorf("parse %s: profile %s has no path", path, profile)
+		}
+	}
+	return c, nil
+}
+
+func newConfig(path string) *Config {
+	return &Config{
+		path:      path,
+		values:    map[string]string{},
+		lists:     map[string][]string{},
+		overrides: map[string]string{},
+	}
+}
+
+// Path returns the file the config was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Profiles returns the names of the profiles defined in the file, sorted.
+func (c *Config) Profiles() []string {
+	seen := map[string]bool{}
+	var names []string
+	for name := range c.values {
+		if profile, _, ok := splitProfileKey(name); ok && !seen[profile] {
+			seen[profile] = true
+			names = append(names, profile)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfiles returns the profiles selected by core.profile: none,
+// one, a comma-separated list, or "all" for every defined profile.
+func (c *Config) ActiveProfiles() ([]string, error) {
+	value := strings.TrimSpace(c.Get("core.profile"))
+	if value == "" {
+		return nil, nil
+	}
+	defined := c.Profiles()
+	if value == "all" {
+		return defined, nil
+	}
+
+	var active []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, d := range defined {
+			if d == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			if len(defined) == 0 {
+				return nil, fmt.Errorf("unknown profile %s (define [profile.%s] in %s)", name, name, c.path)
+			}
+			return nil, fmt.Errorf("unknown profile %s (defined: %s)", name, strings.Join(defined, ", "))
+		}
+		active = append(active, name)
+	}
+	return active, nil
+}
+
+// AllKeys returns the supported keys followed by the keys of every
+// profile defined in the file.
+func (c *Config) AllKeys() []Key {
+	keys := append([]Key(nil), Keys...)
+	for _, profile := range c.Profiles() {
+		for _, pk := range profileKeys {
+			k, _ := Lookup("profile." + profile + "." + pk.Name)
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Override sets a value for this invocation only, above env and file.
+func (c *Config) Override(name, value string) error {
+	k, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown config key: %s", name)
+	}
+	if k.Kind != KindList {
+		if err := k.validate(value); err != nil {
+			return err
+		}
+	}
+	c.overrides[name] = value
+	return nil
+}
+
+// Resolve returns the effective value of name and where it came from.
+// List values are joined with spaces.
+func (c *Config) Resolve(name string) (string, Source) {
+	k, ok := Lookup(name)
+	if !ok {
+		return "", SourceDefault
+	}
+	if v, ok := c.overrides[name]; ok {
+		return v, SourceFlag
+	}
+	if v, ok := k.lookupEnv(); ok {
+		return v, SourceEnv
+	}
+	if k.Kind == KindList {
+		if list, ok := c.lists[name]; ok {
+			return strings.Join(list, " "), SourceFile
+		}
+	} else if v, ok := c.values[name]; ok {
+		return v, SourceFile
+	}
+	return k.Default, SourceDefault
+}
+
+// Get returns the effective value of name.
+func (c *Config) Get(name string) string {
+	v, _ := c.Resolve(name)
+	return v
+}
+
+// GetList returns the effective value of a list key. Flag values and
+// defaults are split on whitespace.
+func (c *Config) GetList(name string) []string {
+	if v, ok := c.overrides[name]; ok {
+		return strings.Fields(v)
+	}
+	if list, ok := c.lists[name]; ok {
+		return list
+	}
+	k, _ := Lookup(name)
+	return strings.Fields(k.Default)
+}
+
+// Set writes name = value to the config file, keeping the rest of the file
+// as it is. List values are split on whitespace.
+func (c *Config) Set(name, value string) error {
+	k, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown config key: %s", name)
+	}
+	// A profile table without a path fails to load, so path comes first
+	if profile, key, ok := splitProfileKey(name); ok {
+		if key == "path" && strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%s cannot be empty", name)
+		}
+		if key != "path" && c.values["profile."+profile+".path"] == "" {
+			return fmt.Errorf("profile %s has no path; set profile.%s.path first", profile, profile)
+		}
+	}
+
+	var rendered string
+	if k.Kind == KindList {
+		list := strings.Fields(value)
+		rendered = formatList(list)
+		c.lists[name] = list
+	} else {
+		if err := k.validate(value); err != nil {
+			return err
+		}
+		rendered = formatValue(k.Kind, value)
+		c.values[name] = value
+	}
+
+	section, key := splitKey(name)
+	data := setLine(c.data, section, key, rendered)
+	if !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data), 0644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write config: %w", err)
+	}
+	c.data = data
+	return nil
+}
+
+// splitKey splits "a.b.c" into table "a.b" and key "c".
+func splitKey(name string) (string, string) {
+	i := strings.LastIndexByte(name, '.')
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
+
+var current *Config
+
+// Init loads the config file for this process and applies "key=value"
+// overrides given on the command line.
+func Init(overrides []string) error {
+	c, err := Load(FilePath())
+	if err != nil {
+		return err
+	}
+	for _, o := range overrides {
+		name, value, ok := strings.Cut(o, "=")
+		if !ok {
+			return fmt.Errorf("invalid config override %q (want key=value)", o)
+		}
+		if err := c.Override(strings.TrimSpace(name), value); err != nil {
+			return err
+		}
+	}
+	if err := c.checkEnv(); err != nil {
+		return err
+	}
+	if _, err := c.ActiveProfiles(); err != nil {
+		return err
+	}
+	current = c
+	return nil
+}
+
+// checkEnv validates values set through environment variables as Load
+// validates the file's, naming the variable. Keys overridden on the
+// command line don't read their variable and are skipped.
+func (c *Config) checkEnv() error {
+	for _, k := range Keys {
+		if _, ok := c.overrides[k.Name]; ok {
+			continue
+		}
+		v, ok := k.lookupEnv()
+		if !ok {
+			continue
+		}
+		if err := k.validate(v); err != nil {
+			return fmt.Errorf("%s: %w", k.EnvName(), err)
+		}
+	}
+	return nil
+}
+
+// Current returns the process config. Without Init, the file is loaded on
+// first use and an unreadable file is treated as empty.
+func Current() *Config {
+	if current == nil {
+		c, err := Load(FilePath())
+		if err != nil {
+			c = newConfig(FilePath())
+		}
+		current = c
+	}
+	return current
+}
+
+// Get returns the effective value of name in the process config.
+func Get(name string) string {
+	return Current().Get(name)
+}
+
+// GetList returns the effective list value of name in the process config.
+func GetList(name string) []string {
+	return Current().GetList(name)
+}
+
+// weightsEnv holds all ranking weights in one variable: "match=1,pin=2".
+const weightsEnv = "TRY_RANK_WEIGHTS"
+
+func weightEnv(name string) func() (string, bool) {
+	return func() (string, bool) {
+		for _, part := range strings.Split(os.Getenv(weightsEnv), ",") {
+			key, value, ok := strings.Cut(part, "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+				return strings.TrimSpace(value), true
+			}
+		}
+		return "", false
+	}
+}
+
+func oneOf(options ...string) func(string) error {
+	return func(value string) error {
+		for _, o := range options {
+			if value == o {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", value, strings.Join(options, ", "))
+	}
+}
+
+func checkDateFormat(layout string) error {
+	ref := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	formatted := ref.Format(layout)
+	if formatted == layout {
+		return fmt.Errorf("%q has no date fields (use a Go layout like 2006-01-02)", layout)
+	}
+	if strings.ContainsAny(formatted, "/ ") {
+		return fmt.Errorf("%q must not produce slashes or spaces", layout)
+	}
+	return nil
+}
+
+func checkNonNegative(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("%q is not a non-negative integer", value)
+	}
+	return nil
+}
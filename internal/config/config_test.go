@@ -0,0 +1,383 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `# try config
+[core]
+path = "~/experiments" # trailing comment
+date_format = '20060102'
+
+[rank]
+match = 2
+pin = 1_0
+
+[clone]
+depth = 1
+args = ["--filter=blob:none", 'a#b']
+`)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"core.path":        "~/experiments",
+		"core.date_format": "20060102",
+		"rank.match":       "2",
+		"rank.pin":         "10",
+		"rank.recency":     "0.5", // default
+		"clone.depth":      "1",
+		"clone.args":       "--filter=blob:none a#b",
+	}
+	for key, expected := range tests {
+		if got := c.Get(key); got != expected {
+			t.Errorf("Get(%s) = %q, expected %q", key, got, expected)
+		}
+	}
+	if got := c.GetList("clone.args"); !reflect.DeepEqual(got, []string{"--filter=blob:none", "a#b"}) {
+		t.Errorf("GetList(clone.args) = %q", got)
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Get("core.path"); got != "~/tries" {
+		t.Errorf("expected default, got %q", got)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown key", "[core]\nroot = \"x\"\n"},
+		{"unquoted string", "[core]\npath = ~/tries\n"},
+		{"bad number", "[rank]\nmatch = \"high\"\n"},
+		{"bad theme", "[ui]\ntheme = \"neon\"\n"},
+		{"bad date format", "[core]\ndate_format = \"date\"\n"},
+		{"list for scalar", "[core]\npath = [\"a\"]\n"},
+		{"scalar for list", "[clone]\nargs = \"--depth 1\"\n"},
+		{"no equals", "[core]\npath\n"},
+		{"bad header", "[core\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Load(writeConfig(t, tc.content)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	c, err := Load(writeConfig(t, "[core]\npath = \"/from/file\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TRY_PATH", "")
+	if v, src := c.Resolve("core.path"); v != "/from/file" || src != SourceFile {
+		t.Errorf("expected file value, got %q from %s", v, src)
+	}
+
+	t.Setenv("TRY_PATH", "/from/env")
+	if v, src := c.Resolve("core.path"); v != "/from/env" || src != SourceEnv {
+		t.Errorf("expected env value, got %q from %s", v, src)
+	}
+
+	if err := c.Override("core.path", "/from/flag"); err != nil {
+		t.Fatal(err)
+	}
+	if v, src := c.Resolve("core.path"); v != "/from/flag" || src != SourceFlag {
+		t.Errorf("expected flag value, got %q from %s", v, src)
+	}
+}
+
+func TestGetList(t *testing.T) {
+	c, err := Load(writeConfig(t, "[clone]\nargs = [\"--recurse-submodules\"]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.GetList("clone.args"); !reflect.DeepEqual(got, []string{"--recurse-submodules"}) {
+		t.Errorf("clone.args = %q, expected file value", got)
+	}
+	if got := c.GetList("clean.patterns"); len(got) == 0 || got[0] != "node_modules" {
+		t.Errorf("clean.patterns = %q, expected defaults", got)
+	}
+	if err := c.Override("clean.patterns", "target  dist"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetList("clean.patterns"); !reflect.DeepEqual(got, []string{"target", "dist"}) {
+		t.Errorf("clean.patterns = %q, expected flag value", got)
+	}
+}
+
+func TestResolve_WeightsEnv(t *testing.T) {
+	c, err := Load(writeConfig(t, "[rank]\nmatch = 3\npin = 4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRY_RANK_WEIGHTS", "match=2, frecency=0")
+
+	if v, src := c.Resolve("rank.match"); v != "2" || src != SourceEnv {
+		t.Errorf("rank.match = %q from %s, expected env value", v, src)
+	}
+	if v, src := c.Resolve("rank.pin"); v != "4" || src != SourceFile {
+		t.Errorf("rank.pin = %q from %s, expected file value", v, src)
+	}
+	if v := c.Get("rank.frecency"); v != "0" {
+		t.Errorf("rank.frecency = %q, expected 0", v)
+	}
+}
+
+func TestInit_InvalidEnv(t *testing.T) {
+	t.Setenv("TRY_CONFIG", writeConfig(t, ""))
+	t.Cleanup(func() { current = nil })
+
+	tests := []struct {
+		env, value, expected string
+	}{
+		{"TRY_THEME", "neon", `TRY_THEME: ui.theme: "neon" is not one of default, mono`},
+		{"TRY_DATE_FORMAT", "today", "TRY_DATE_FORMAT: core.date_format:"},
+		{"TRY_RANK_WEIGHTS", "match=lots", `TRY_RANK_WEIGHTS: rank.match: "lots" is not a number`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.env, func(t *testing.T) {
+			t.Setenv(tc.env, tc.value)
+			err := Init(nil)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.expected) {
+				t.Errorf("Init() with %s=%s error = %v, expected %q", tc.env, tc.value, err, tc.expected)
+			}
+		})
+	}
+
+	// Valid values pass, and a flag override means the variable isn't read
+	t.Setenv("TRY_THEME", "mono")
+	if err := Init(nil); err != nil {
+		t.Errorf("Init() with a valid env value error = %v", err)
+	}
+	t.Setenv("TRY_THEME", "neon")
+	if err := Init([]string{"ui.theme=default"}); err != nil {
+		t.Errorf("Init() with the env value overridden error = %v", err)
+	}
+}
+
+func TestOverride_Invalid(t *testing.T) {
+	c, _ := Load("")
+	if err := c.Override("core.nope", "x"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+	if err := c.Override("clone.depth", "-1"); err == nil {
+		t.Error("expected error for negative depth")
+	}
+}
+
+func TestSet_PreservesFile(t *testing.T) {
+	original := `# my settings
+[core]
+path = "~/tries" # where experiments live
+
+[ui]
+theme = "default"
+`
+	path := writeConfig(t, original)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kv := range [][2]string{
+		{"core.path", "~/lab"},
+		{"core.date_format", "20060102"},
+		{"clone.args", "--recurse-submodules --filter=blob:none"},
+		{"rank.match", "1.5"},
+	} {
+		if err := c.Set(kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%s): %v", kv[0], err)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	for _, want := range []string{
+		"# my settings\n[core]\npath = \"~/lab\"\ndate_format = \"20060102\"\n",
+		"[ui]\ntheme = \"default\"\n",
+		"[clone]\nargs = [\"--recurse-submodules\", \"--filter=blob:none\"]\n",
+		"[rank]\nmatch = 1.5\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in:\n%s", want, content)
+		}
+	}
+
+	// The written file must load back to the same values
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("reload: %v\n%s", err, content)
+	}
+	if got := reloaded.Get("core.path"); got != "~/lab" {
+		t.Errorf("core.path = %q after reload", got)
+	}
+	if got := reloaded.GetList("clone.args"); len(got) != 2 {
+		t.Errorf("clone.args = %q after reload", got)
+	}
+}
+
+func TestSet_CreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "try", "config.toml")
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("hooks.post_create", `echo "ready"`); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Get("hooks.post_create"); got != `echo "ready"` {
+		t.Errorf("hooks.post_create = %q", got)
+	}
+}
+
+func TestSet_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	c, _ := Load(path)
+	if err := c.Set("ui.theme", "neon"); err == nil {
+		t.Error("expected error for invalid theme")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("invalid value should not create the file")
+	}
+}
+
+func TestSet_ProfileNeedsPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	c, _ := Load(path)
+	if err := c.Set("profile.work.projects", "~/work/src"); err == nil || !strings.Contains(err.Error(), "set profile.work.path first") {
+		t.Errorf("expected error asking for the path first, got %v", err)
+	}
+	if err := c.Set("profile.work.path", " "); err == nil {
+		t.Error("expected error for an empty path")
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatalf("refused sets should leave the file loadable: %v", err)
+	}
+
+	if err := c.Set("profile.work.path", "~/work/tries"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("profile.work.projects", "~/work/src"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Get("profile.work.projects"); got != "~/work/src" {
+		t.Errorf("profile.work.projects = %q", got)
+	}
+}
+
+func TestFilePath(t *testing.T) {
+	t.Setenv("TRY_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got := FilePath(); got != "/xdg/try/config.toml" {
+		t.Errorf("FilePath() = %q", got)
+	}
+
+	t.Setenv("TRY_CONFIG", "/custom.toml")
+	if got := FilePath(); got != "/custom.toml" {
+		t.Errorf("FilePath() = %q", got)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	c, err := Load(writeConfig(t, `[profile.work]
+path = "~/work/tries"
+projects = "~/work/src"
+
+[profile.oss]
+path = "~/oss/tries"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.Profiles(); !reflect.DeepEqual(got, []string{"oss", "work"}) {
+		t.Errorf("Profiles() = %q", got)
+	}
+	if got := c.Get("profile.work.projects"); got != "~/work/src" {
+		t.Errorf("profile.work.projects = %q", got)
+	}
+
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{"", nil},
+		{"work", []string{"work"}},
+		{"oss, work", []string{"oss", "work"}},
+		{"all", []string{"oss", "work"}},
+	}
+	for _, tc := range tests {
+		t.Setenv("TRY_PROFILE", tc.value)
+		got, err := c.ActiveProfiles()
+		if err != nil {
+			t.Fatalf("ActiveProfiles(%q): %v", tc.value, err)
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("ActiveProfiles(%q) = %q, expected %q", tc.value, got, tc.expected)
+		}
+	}
+
+	t.Setenv("TRY_PROFILE", "home")
+	if _, err := c.ActiveProfiles(); err == nil || !strings.Contains(err.Error(), "oss, work") {
+		t.Errorf("expected unknown profile error listing profiles, got %v", err)
+	}
+
+	if got := len(c.AllKeys()); got != len(Keys)+4 {
+		t.Errorf("AllKeys() has %d keys, expected %d", got, len(Keys)+4)
+	}
+}
+
+func TestProfiles_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing path": "[profile.work]\nprojects = \"~/src\"\n",
+		"unknown key":  "[profile.work]\npath = \"~/w\"\ncolor = \"red\"\n",
+		"bad name":     "[profile.\"my work\"]\npath = \"~/w\"\n",
+		"all reserved": "[profile.all]\npath = \"~/w\"\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(writeConfig(t, content)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file implements the small TOML subset try's config needs, one
+// statement per line:
+//
+//   - [table] and [dotted.table] headers, each defined once
+//   - key = value, where keys and table names are bare: letters, digits,
+//     '-' and '_', joined by '.'; quoted keys are rejected
+//   - "basic" strings with TOML escapes (\b \t \n \f \r \" \\ \uXXXX
+//     \UXXXXXXXX) and 'literal' strings taken as written
+//   - true and false; decimal numbers, with optional '_' between digits
+//   - one-line arrays of strings, a trailing comma allowed
+//   - # comments on their own line or after a value
+//
+// Setting a key twice, also through a dotted key, is an error, as in TOML.
+// Multi-line strings, inline tables, arrays of tables and dates are not
+// supported and are reported with their line number.
+
+// parseTOML returns the scalar values and string arrays keyed by dotted path.
+func parseTOML(data string) (map[string]string, map[string][]string, error) {
+	values := map[string]string{}
+	lists := map[string][]string{}
+	defined := map[string]int{} // Key or table to the line defining it
+	section := ""
+
+	for i, raw := range strings.Split(data, "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, nil, fmt.Errorf("line %d: invalid table header %q", lineNo, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == "" {
+				return nil, nil, fmt.Errorf("line %d: empty table name", lineNo)
+			}
+			if !validKey(section) {
+				return nil, nil, fmt.Errorf("line %d: invalid table name %q (only bare names are supported)", lineNo, section)
+			}
+			if first, ok := defined["["+section+"]"]; ok {
+				return nil, nil, fmt.Errorf("line %d: table [%s] already defined on line %d", lineNo, section, first)
+			}
+			defined["["+section+"]"] = lineNo
+			continue
+		}
+
+		key, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, nil, fmt.Errorf("line %d: missing key", lineNo)
+		}
+		if !validKey(key) {
+			return nil, nil, fmt.Errorf("line %d: invalid key %q (only bare keys are supported)", lineNo, key)
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		if first, ok := defined[key]; ok {
+			return nil, nil, fmt.Errorf("line %d: %s already set on line %d", lineNo, key, first)
+		}
+		defined[key] = lineNo
+
+		rawValue = strings.TrimSpace(rawValue)
+		if strings.HasPrefix(rawValue, "[") {
+			list, err := parseArray(rawValue)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			lists[key] = list
+			continue
+		}
+		value, err := parseScalar(rawValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		values[key] = value
+	}
+	return values, lists, nil
+}
+
+// validKey reports whether key is one or more bare keys joined by dots.
+func validKey(key string) bool {
+	for _, part := range strings.Split(key, ".") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// stripComment removes a trailing # comment that is not inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseScalar(s string) (string, error) {
+	switch {
+	case s == "":
+		return "", fmt.Errorf("missing value")
+	case strings.HasPrefix(s, `"`):
+		if closingQuote(s) != len(s)-1 {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		return unquoteBasic(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") || strings.Contains(s[1:len(s)-1], "'") {
+			return "", fmt.Errorf("invalid literal string %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	case s == "true" || s == "false":
+		return s, nil
+	default:
+		if !validNumber(s) {
+			return "", fmt.Errorf("invalid value %s (strings must be quoted)", s)
+		}
+		return strings.ReplaceAll(s, "_", ""), nil
+	}
+}
+
+// validNumber reports whether s is a decimal integer or float, with '_'
+// only between digits.
+func validNumber(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '_' && (i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1])) {
+			return false
+		}
+	}
+	s = strings.ReplaceAll(s, "_", "")
+	digits := strings.TrimLeft(s, "+-")
+	if digits == "" || !isDigit(digits[0]) {
+		return false // Rejects inf, nan and the like
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil && !strings.ContainsAny(s, "xXpP")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// unquoteBasic decodes the basic string s, quotes included, using TOML's
+// escapes rather than Go's.
+func unquoteBasic(s string) (string, error) {
+	var b strings.Builder
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			if c < 0x20 && c != '\t' || c == 0x7f {
+				return "", fmt.Errorf("control character in string %s", s)
+			}
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(body) {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		switch body[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '"':
+			b.WriteByte('"')
+		case '\\':
+			b.WriteByte('\\')
+		case 'u', 'U':
+			n := 4
+			if body[i] == 'U' {
+				n = 8
+			}
+			if i+n >= len(body) {
+				return "", fmt.Errorf("invalid escape in string %s", s)
+			}
+			code, err := strconv.ParseUint(body[i+1:i+1+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid escape in string %s", s)
+			}
+			b.WriteRune(rune(code))
+			i += n
+		default:
+			return "", fmt.Errorf("invalid escape \\%c in string %s", body[i], s)
+		}
+	}
+	return b.String(), nil
+}
+
+// quoteBasic renders v as a basic string, escaping what TOML requires.
+func quoteBasic(v string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range v {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func parseArray(s string) ([]string, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("arrays must be on one line: %s", s)
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	list := []string{}
+	for inner != "" {
+		var item string
+		var rest string
+		switch inner[0] {
+		case '"':
+			end := closingQuote(inner)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in %s", s)
+			}
+			v, err := unquoteBasic(inner[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string in %s", s)
+			}
+			item, rest = v, inner[end+1:]
+		case '\'':
+			end := strings.IndexByte(inner[1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in %s", s)
+			}
+			item, rest = inner[1:end+1], inner[end+2:]
+		default:
+			return nil, fmt.Errorf("arrays may only contain strings: %s", s)
+		}
+		list = append(list, item)
+
+		rest = strings.TrimSpace(rest)
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("expected , in %s", s)
+			}
+			rest = strings.TrimSpace(rest[1:])
+		}
+		inner = rest
+	}
+	return list, nil
+}
+
+// closingQuote returns the index of the quote closing the basic string at s[0].
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// formatValue renders a value as TOML according to its kind.
+func formatValue(kind Kind, value string) string {
+	switch kind {
+	case KindNumber, KindBool:
+		return value
+	default:
+		return quoteBasic(value)
+	}
+}
+
+// formatList renders a string array as TOML.
+func formatList(list []string) string {
+	quoted := make([]string, len(list))
+	for i, item := range list {
+		quoted[i] = quoteBasic(item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// setLine returns data with section.key set to the rendered value,
+// replacing an existing assignment or adding one, and keeping comments
+// and layout of the rest of the file.
+func setLine(data, section, key, rendered string) string {
+	lines := strings.Split(data, "\n")
+	assignment := key + " = " + rendered
+
+	current := ""
+	sectionEnd := -1 // index after the last line belonging to section
+	for i, raw := range lines {
+		line := strings.TrimSpace(stripComment(raw))
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if line != "" {
+			sectionEnd = i + 1
+		}
+		if k, _, ok := strings.Cut(line, "="); ok && strings.TrimSpace(k) == key {
+			lines[i] = assignment
+			return strings.Join(lines, "\n")
+		}
+	}
+
+	if sectionEnd < 0 {
+		// Find the header of an empty section, if present
+		current = ""
+		for i, raw := range lines {
+			line := strings.TrimSpace(stripComment(raw))
+			if strings.HasPrefix(line, "[") && strings.TrimSpace(strings.Trim(line, "[]")) == section {
+				sectionEnd = i + 1
+				break
+			}
+		}
+	}
+
+	if sectionEnd < 0 {
+		if section == "" {
+			// Top-level keys must come before any table
+			return assignment + "\n" + data
+		}
+		text := strings.TrimRight(data, "\n")
+		if text != "" {
+			text += "\n\n"
+		}
+		return text + "[" + section + "]\n" + assignment + "\n"
+	}
+
+	lines = append(lines[:sectionEnd], append([]string{assignment}, lines[sectionEnd:]...)...)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	values, lists, err := parseTOML(`# top comment
+top = 1
+dotted.key = "x"
+
+[core]
+path = "~/tries # not a comment"  # comment after a value
+literal = 'C:\tries\n'             # literal strings keep backslashes
+quote = 'say "hi"'
+escapes = "tab\there\nquote\" slash\\ \u00e9\U0001F680"
+hash = "a#b"
+number = 1_000
+float = -0.5
+exp = 1e3
+flag = true
+empty = ""
+
+[clean]
+patterns = ["node_modules", 'tar\get', "a,b", ]  # trailing comma
+none = []
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"top":          "1",
+		"dotted.key":   "x",
+		"core.path":    "~/tries # not a comment",
+		"core.literal": `C:\tries\n`,
+		"core.quote":   `say "hi"`,
+		"core.escapes": "tab\there\nquote\" slash\\ é🚀",
+		"core.hash":    "a#b",
+		"core.number":  "1000",
+		"core.float":   "-0.5",
+		"core.exp":     "1e3",
+		"core.flag":    "true",
+		"core.empty":   "",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("values:\n got %q\nwant %q", values, expected)
+	}
+	expectedLists := map[string][]string{
+		"clean.patterns": {"node_modules", `tar\get`, "a,b"},
+		"clean.none":     {},
+	}
+	if !reflect.DeepEqual(lists, expectedLists) {
+		t.Errorf("lists:\n got %q\nwant %q", lists, expectedLists)
+	}
+}
+
+func TestParseTOML_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"duplicate key", "[ui]\ntheme = \"a\"\ntheme = \"b\"\n", "line 3: ui.theme already set on line 2"},
+		{"duplicate dotted key", "ui.theme = \"a\"\n[ui]\ntheme = \"b\"\n", "line 3: ui.theme already set on line 1"},
+		{"duplicate array", "[clean]\npatterns = []\npatterns = [\"a\"]\n", "already set on line 2"},
+		{"duplicate table", "[ui]\ntheme = \"a\"\n[ui]\npreview = true\n", "line 3: table [ui] already defined on line 1"},
+		{"quoted key", "[ui]\n\"theme\" = \"a\"\n", "line 2: invalid key"},
+		{"quoted table", "[profile.\"my work\"]\n", "line 1: invalid table name"},
+		{"unquoted string", "[ui]\ntheme = mono\n", "strings must be quoted"},
+		{"unknown escape", `a = "\x41"`, `invalid escape \x`},
+		{"short unicode escape", `a = "\u41"`, "invalid escape"},
+		{"surrogate escape", `a = "\uD800"`, "invalid escape"},
+		{"unterminated", `a = "abc`, "invalid string"},
+		{"text after string", `a = "abc" x`, "invalid string"},
+		{"multi-line string", `a = """abc"""`, "invalid string"},
+		{"quote in literal", `a = 'it's'`, "invalid literal string"},
+		{"inline table", "a = { b = 1 }", "strings must be quoted"},
+		{"date", "a = 2024-01-15", "strings must be quoted"},
+		{"infinity", "a = inf", "strings must be quoted"},
+		{"hex", "a = 0x10", "strings must be quoted"},
+		{"bad underscore", "a = 1__0", "strings must be quoted"},
+		{"multi-line array", "a = [\n\"b\"]\n", "arrays must be on one line"},
+		{"array of numbers", "a = [1, 2]", "arrays may only contain strings"},
+		{"array of tables", "[[a]]", "invalid table header"},
+		{"missing value", "a =", "missing value"},
+		{"no equals", "a", "expected key = value"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := parseTOML(tc.data)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestQuoteBasic_RoundTrip(t *testing.T) {
+	for _, v := range []string{"", "plain", `say "hi"`, `C:\tries`, "tab\tnew\nline\r", "bell\a del\x7f", "é🚀", "# not a comment"} {
+		quoted := quoteBasic(v)
+		values, _, err := parseTOML("v = " + quoted + " # comment\n")
+		if err != nil {
+			t.Errorf("%q: quoted as %s: %v", v, quoted, err)
+			continue
+		}
+		if values["v"] != v {
+			t.Errorf("%q: quoted as %s, read back %q", v, quoted, values["v"])
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package du
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+)
+
+// Result is the outcome of sizing one directory.
+type Result struct {
+	Path string
+	Size int64
+	Err  error
+}
+
+// Size returns the total size in bytes of the files under path. Symlinks
+// are counted as links and never followed, so a graduated entry costs
+// nothing here. Unreadable subdirectories are skipped. It stops early
+// with ctx.Err() when ctx is cancelled.
+func Size(ctx context.Context, path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == path {
+				return err
+			}
+			return nil // Skip what we can't read
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed while walking
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// SizeAll sizes every path using at most workers concurrent walks.
+// Results arrive in completion order; the channel is closed when all are
+// done or ctx is cancelled. It is buffered for every result, so workers
+// finish even if nobody reads.
+func SizeAll(ctx context.Context, paths []string, workers int) <-chan Result {
+	results := make(chan Result, len(paths))
+	jobs := make(chan string)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				size, err := Size(ctx, path)
+				if ctx.Err() != nil {
+					return
+				}
+				results <- Result{Path: path, Size: size, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(results)
+		}()
+		for _, path := range paths {
+			select {
+			case jobs <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// Format renders a byte count compactly, e.g. "512B", "3.4K", "1.2G".
+func Format(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	value := float64(n) / float64(div)
+	if value >= 10 {
+		return fmt.Sprintf("%.0f%c", value, "KMGTP"[exp])
+	}
+	return fmt.Sprintf("%.1f%c", value, "KMGTP"[exp])
+}
@@ -0,0 +1,109 @@
+package du
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, "exp")
+	writeFile(t, filepath.Join(dir, "a"), 100)
+	writeFile(t, filepath.Join(dir, "node_modules", "pkg", "b"), 250)
+
+	// Symlinks are not followed
+	outside := filepath.Join(tmpDir, "outside")
+	writeFile(t, filepath.Join(outside, "big"), 10000)
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := Size(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Size() error = %v", err)
+	}
+	if size != 350 {
+		t.Errorf("Size() = %d, expected 350", size)
+	}
+}
+
+func TestSize_Errors(t *testing.T) {
+	if _, err := Size(context.Background(), filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a"), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Size(ctx, dir); !errors.Is(err, context.Canceled) {
+		t.Errorf("Size() with cancelled context error = %v, expected context.Canceled", err)
+	}
+}
+
+func TestSizeAll(t *testing.T) {
+	tmpDir := t.TempDir()
+	var paths []string
+	for i, name := range []string{"one", "two", "three"} {
+		path := filepath.Join(tmpDir, name)
+		writeFile(t, filepath.Join(path, "f"), (i+1)*10)
+		paths = append(paths, path)
+	}
+
+	sizes := map[string]int64{}
+	for r := range SizeAll(context.Background(), paths, 2) {
+		if r.Err != nil {
+			t.Fatalf("SizeAll() error for %s: %v", r.Path, r.Err)
+		}
+		sizes[filepath.Base(r.Path)] = r.Size
+	}
+
+	expected := map[string]int64{"one": 10, "two": 20, "three": 30}
+	for name, size := range expected {
+		if sizes[name] != size {
+			t.Errorf("size of %s = %d, expected %d", name, sizes[name], size)
+		}
+	}
+}
+
+func TestSizeAll_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// The channel must still close
+	for range SizeAll(ctx, []string{t.TempDir(), t.TempDir()}, 1) {
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{0, "0B"},
+		{512, "512B"},
+		{1024, "1.0K"},
+		{3500, "3.4K"},
+		{20 * 1024, "20K"},
+		{5 << 20, "5.0M"},
+		{1288490189, "1.2G"},
+		{3 << 40, "3.0T"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.n); got != tt.expected {
+			t.Errorf("Format(%d) = %q, expected %q", tt.n, got, tt.expected)
+		}
+	}
+}
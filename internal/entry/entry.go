@@ -2,6 +2,7 @@ package entry
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -9,47 +10,88 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/xpzouying/try/internal/archive"
+	"github.com/xpzouying/try/internal/config"
+	"github.com/xpzouying/try/internal/frecency"
+	"github.com/xpzouying/try/internal/guard"
+	"github.com/xpzouying/try/internal/meta"
 )
 
 // Entry represents a directory in the tries folder.
 type Entry struct {
-	Name       string    // Directory name (e.g., "2024-01-15-redis")
-	Path       string    // Full path
-	ModTime    time.Time // Last modification time
-	HasDate    bool      // Whether name starts with date prefix
-	BaseName   string    // Name without date prefix (e.g., "redis")
-	IsWorktree bool      // Whether this is a git worktree
-	SourceRepo string    // For worktrees: name of the source repository
+	Name       string         // Directory name (e.g., "2024-01-15-redis")
+	Path       string         // Full path
+	ModTime    time.Time      // Last modification time
+	HasDate    bool           // Whether name starts with date prefix
+	BaseName   string         // Name without date prefix (e.g., "redis")
+	IsWorktree bool           // Whether this is a git worktree
+	SourceRepo string         // For worktrees: name of the source repository
+	Meta       *meta.Meta     // Recorded metadata, nil if none
+	Visits     frecency.Visit // Visit history from the tries visit log
+	Profile    string         // Profile of the root, set when several roots are merged
+	Archived   bool           // Packed into an archive; Path is the archive file
+	Target     string         // For graduated entries: where the symlink points
+	Broken     bool           // For graduated entries: the target no longer exists
 }
 
 var datePrefix = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-`)
 
-// NewEntry creates an Entry from a directory path.
+// NewEntry creates an Entry from a directory path. A symlink left by
+// graduate becomes an entry with Target set, and Broken if the project
+// was moved or removed since.
 func NewEntry(path string) (*Entry, error) {
-	info, err := os.Stat(path)
+	linkInfo, err := os.Lstat(path)
 	if err != nil {
 		return nil, err
 	}
-	if !info.IsDir() {
-		return nil, nil
+	target := ""
+	if linkInfo.Mode()&os.ModeSymlink != 0 {
+		if target, err = os.Readlink(path); err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
 	}
 
 	name := filepath.Base(path)
-	hasDate := datePrefix.MatchString(name)
-	baseName := name
-	if hasDate {
-		baseName = name[11:] // Remove "YYYY-MM-DD-" prefix
+	baseName, hasDate := splitDate(name)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if target == "" || !os.IsNotExist(err) {
+			return nil, err
+		}
+		m, _ := meta.Load(filepath.Dir(path), name)
+		return &Entry{
+			Name:     name,
+			Path:     path,
+			ModTime:  linkInfo.ModTime(),
+			HasDate:  hasDate,
+			BaseName: baseName,
+			Meta:     m,
+			Target:   target,
+			Broken:   true,
+		}, nil
+	}
+	if !info.IsDir() {
+		return nil, nil
 	}
 
-	// Detect if this is a git worktree (.git is a file, not a directory)
+	// Detect if this is a git worktree (.git is a file, not a directory).
+	// A graduated symlink is never one, whatever it points to.
 	isWorktree := false
 	sourceRepo := ""
 	gitPath := filepath.Join(path, ".git")
-	if gitInfo, err := os.Stat(gitPath); err == nil && !gitInfo.IsDir() {
+	if gitInfo, err := os.Stat(gitPath); err == nil && !gitInfo.IsDir() && target == "" {
 		isWorktree = true
 		sourceRepo = parseWorktreeSource(gitPath)
 	}
 
+	// Metadata is optional; a corrupt file shouldn't hide the entry
+	m, _ := meta.Load(filepath.Dir(path), name)
+
 	return &Entry{
 		Name:       name,
 		Path:       path,
@@ -58,6 +100,8 @@ func NewEntry(path string) (*Entry, error) {
 		BaseName:   baseName,
 		IsWorktree: isWorktree,
 		SourceRepo: sourceRepo,
+		Meta:       m,
+		Target:     target,
 	}, nil
 }
 
@@ -71,9 +115,16 @@ func LoadEntries(triesPath string) ([]*Entry, error) {
 		return nil, err
 	}
 
+	// Visit history is optional; a corrupt log just means no frecency
+	visits, err := frecency.Load(triesPath)
+	if err != nil {
+		visits = &frecency.Log{}
+	}
+
 	var result []*Entry
 	for _, e := range entries {
-		if !e.IsDir() || e.Name()[0] == '.' {
+		// Symlinks are graduated entries
+		if (!e.IsDir() && e.Type()&os.ModeSymlink == 0) || e.Name()[0] == '.' {
 			continue
 		}
 		entry, err := NewEntry(filepath.Join(triesPath, e.Name()))
@@ -81,6 +132,7 @@ func LoadEntries(triesPath string) ([]*Entry, error) {
 			continue
 		}
 		if entry != nil {
+			entry.Visits = visits.Get(entry.Name)
 			result = append(result, entry)
 		}
 	}
@@ -93,6 +145,68 @@ func LoadEntries(triesPath string) ([]*Entry, error) {
 	return result, nil
 }
 
+// LoadAll loads the entries of every active root. When several roots are
+// merged, each entry records its profile.
+func LoadAll() ([]*Entry, error) {
+	roots := Roots()
+	if len(roots) == 1 {
+		return LoadEntries(roots[0].Path)
+	}
+
+	var result []*Entry
+	for _, root := range roots {
+		entries, err := LoadEntries(root.Path)
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", root.Profile, err)
+		}
+		for _, e := range entries {
+			e.Profile = root.Profile
+		}
+		result = append(result, entries...)
+	}
+
+	// Sort by modification time (newest first)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ModTime.After(result[j].ModTime)
+	})
+
+	return result, nil
+}
+
+// LoadArchived returns the archived experiments of every active root as
+// entries with Archived set, newest archive first.
+func LoadArchived() ([]*Entry, error) {
+	roots := Roots()
+	var result []*Entry
+	for _, root := range roots {
+		archives, err := archive.List(root.Path)
+		if err != nil {
+			return nil, fmt.Errorf("list archives: %w", err)
+		}
+		for _, a := range archives {
+			baseName, hasDate := splitDate(a.Name)
+			e := &Entry{
+				Name:     a.Name,
+				Path:     a.Path,
+				ModTime:  a.ArchivedAt,
+				HasDate:  hasDate,
+				BaseName: baseName,
+				Meta:     a.Meta,
+				Archived: true,
+			}
+			if len(roots) > 1 {
+				e.Profile = root.Profile
+			}
+			result = append(result, e)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ModTime.After(result[j].ModTime)
+	})
+	return result, nil
+}
+
 // LoadWorktreesForRepo loads worktrees in tries directory that belong to the given repo.
 func LoadWorktreesForRepo(repoPath string) ([]*Entry, error) {
 	triesPath := TriesPath()
@@ -176,21 +290,157 @@ func (e *Entry) Score(now time.Time) float64 {
 	return score
 }
 
-// TriesPath returns the configured tries directory path.
-func TriesPath() string {
-	if path := os.Getenv("TRY_PATH"); path != "" {
-		return expandHome(path)
+// Frecency returns the visit-based frecency score at now.
+func (e *Entry) Frecency(now time.Time) float64 {
+	return e.Visits.Score(now)
+}
+
+// Description returns the recorded description, if any.
+func (e *Entry) Description() string {
+	if e.Meta == nil {
+		return ""
+	}
+	return e.Meta.Description
+}
+
+// Tags returns the recorded tags, if any.
+func (e *Entry) Tags() []string {
+	if e.Meta == nil {
+		return nil
+	}
+	return e.Meta.Tags
+}
+
+// Graduated reports whether the entry is a symlink left by graduate.
+func (e *Entry) Graduated() bool {
+	return e.Target != ""
+}
+
+// Pinned reports whether the entry is pinned to the top of the ranking.
+func (e *Entry) Pinned() bool {
+	return e.Meta != nil && e.Meta.Pinned
+}
+
+// HasTags reports whether the entry carries every given tag.
+func (e *Entry) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if e.Meta == nil || !e.Meta.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Root is a tries directory and where its experiments graduate to.
+type Root struct {
+	Profile  string // Profile name, empty for core.path
+	Path     string
+	Projects string
+}
+
+// Roots returns the active tries roots: one per profile selected by
+// core.profile, or core.path when no profile is active. New experiments
+// go to the first root.
+func Roots() []Root {
+	profiles, _ := config.Current().ActiveProfiles()
+	if len(profiles) == 0 {
+		return []Root{newRoot("", config.Get("core.path"), config.Get("core.projects"))}
+	}
+
+	roots := make([]Root, len(profiles))
+	for i, p := range profiles {
+		prefix := "profile." + p + "."
+		roots[i] = newRoot(p, config.Get(prefix+"path"), config.Get(prefix+"projects"))
+	}
+	return roots
+}
+
+// AllRoots returns core.path followed by the root of every defined
+// profile, whether active or not.
+func AllRoots() []Root {
+	roots := []Root{newRoot("", config.Get("core.path"), config.Get("core.projects"))}
+	for _, p := range config.Current().Profiles() {
+		prefix := "profile." + p + "."
+		roots = append(roots, newRoot(p, config.Get(prefix+"path"), config.Get(prefix+"projects")))
 	}
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, "tries")
+	return roots
 }
 
-// ProjectsPath returns the configured projects directory path.
+// CheckContained returns an error unless path is safely inside one of
+// the configured roots, so it may be deleted or moved. See guard.Check.
+func CheckContained(path string) error {
+	var paths []string
+	for _, root := range AllRoots() {
+		paths = append(paths, root.Path)
+	}
+	return guard.Check(path, paths)
+}
+
+// CheckSource returns an error if moving the directory at path would
+// take /, $HOME or a configured root with it. See guard.CheckSource.
+func CheckSource(path string) error {
+	var paths []string
+	for _, root := range AllRoots() {
+		paths = append(paths, root.Path)
+	}
+	return guard.CheckSource(path, paths)
+}
+
+func newRoot(profile, path, projects string) Root {
+	root := Root{Profile: profile, Path: filepath.Clean(expandHome(path)), Projects: expandHome(projects)}
+	if root.Projects == "" {
+		root.Projects = filepath.Dir(root.Path)
+	}
+	return root
+}
+
+// RootOf returns the active root containing path, or the first root.
+func RootOf(path string) Root {
+	roots := Roots()
+	dir := filepath.Dir(filepath.Clean(path))
+	for _, root := range roots {
+		if root.Path == dir {
+			return root
+		}
+	}
+	return roots[0]
+}
+
+// TriesPath returns the tries directory new experiments are created in
+// (core.path, or the path of the first active profile).
+func TriesPath() string {
+	return Roots()[0].Path
+}
+
+// ProjectsPath returns the graduate destination of TriesPath
+// (core.projects, or the projects of the first active profile).
 func ProjectsPath() string {
-	if path := os.Getenv("TRY_PROJECTS"); path != "" {
-		return expandHome(path)
+	return Roots()[0].Projects
+}
+
+// DateFormat returns the Go time layout of new experiments' date prefix (core.date_format).
+func DateFormat() string {
+	return config.Get("core.date_format")
+}
+
+// DatePrefix returns the date prefix for an experiment created at t.
+func DatePrefix(t time.Time) string {
+	return t.Format(DateFormat())
+}
+
+// splitDate removes a "YYYY-MM-DD-" or configured date prefix from name.
+func splitDate(name string) (string, bool) {
+	if datePrefix.MatchString(name) {
+		return name[11:], true
+	}
+	layout := DateFormat()
+	n := len(time.Now().Format(layout))
+	if n < len(name) && name[n] == '-' {
+		if _, err := time.Parse(layout, name[:n]); err == nil {
+			return name[n+1:], true
+		}
 	}
-	return filepath.Dir(TriesPath())
+	return name, false
 }
 
 func expandHome(path string) string {
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/xpzouying/try/internal/config"
+	"github.com/xpzouying/try/internal/meta"
 )
 
 func TestNewEntry_BasicDirectory(t *testing.T) {
@@ -117,6 +120,49 @@ func TestLoadEntries_Basic(t *testing.T) {
 	}
 }
 
+func TestLoadEntries_Graduated(t *testing.T) {
+	tmpDir := t.TempDir()
+	tries := filepath.Join(tmpDir, "tries")
+	project := filepath.Join(tmpDir, "projects", "redis")
+	for _, dir := range []string{tries, project} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"2024-01-15-redis": project,
+		"2024-01-14-gone":  filepath.Join(tmpDir, "projects", "gone"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(tries, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A symlink to a file is not an entry
+	if err := os.WriteFile(filepath.Join(tmpDir, "notes.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(tmpDir, "notes.txt"), filepath.Join(tries, "notes")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := LoadEntries(tries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 graduated entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if !e.Graduated() || e.Target != links[e.Name] {
+			t.Errorf("%s: expected target %s, got %q", e.Name, links[e.Name], e.Target)
+		}
+		if e.Broken != (e.Name == "2024-01-14-gone") {
+			t.Errorf("%s: Broken = %v", e.Name, e.Broken)
+		}
+	}
+}
+
 func TestLoadEntries_NonExistent(t *testing.T) {
 	entries, err := LoadEntries("/nonexistent/path")
 	if err != nil {
@@ -253,6 +299,33 @@ func TestDatePrefixRegex(t *testing.T) {
 	}
 }
 
+func TestSplitDate_CustomFormat(t *testing.T) {
+	t.Setenv("TRY_DATE_FORMAT", "20060102")
+
+	tests := []struct {
+		input    string
+		baseName string
+		hasDate  bool
+	}{
+		{"20240115-redis", "redis", true},
+		{"2024-01-15-redis", "redis", true}, // Default format is always recognized
+		{"20241315-redis", "20241315-redis", false}, // Month 13
+		{"20240115redis", "20240115redis", false},
+		{"redis", "redis", false},
+	}
+
+	for _, tc := range tests {
+		baseName, hasDate := splitDate(tc.input)
+		if baseName != tc.baseName || hasDate != tc.hasDate {
+			t.Errorf("splitDate(%s) = %q, %v; expected %q, %v", tc.input, baseName, hasDate, tc.baseName, tc.hasDate)
+		}
+	}
+
+	if got := DatePrefix(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)); got != "20240115" {
+		t.Errorf("DatePrefix = %s, expected 20240115", got)
+	}
+}
+
 func TestNewEntry_Worktree(t *testing.T) {
 	tmpDir := t.TempDir()
 	worktreeDir := filepath.Join(tmpDir, "2024-01-15-feature")
@@ -306,3 +379,120 @@ func TestParseWorktreeSource(t *testing.T) {
 		}
 	}
 }
+
+func TestNewEntry_Meta(t *testing.T) {
+	tmpDir := t.TempDir()
+	testDir := filepath.Join(tmpDir, "2024-01-15-redis")
+	if err := os.Mkdir(testDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// No metadata recorded
+	entry, err := NewEntry(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Meta != nil || entry.Description() != "" {
+		t.Error("expected no metadata")
+	}
+
+	m := meta.New(meta.Origin{Kind: meta.OriginMkdir})
+	m.Description = "cluster failover"
+	if err := meta.Save(tmpDir, "2024-01-15-redis", m); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err = NewEntry(testDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Description() != "cluster failover" {
+		t.Errorf("expected description, got %q", entry.Description())
+	}
+	if entry.Meta.Origin.Kind != meta.OriginMkdir {
+		t.Errorf("expected mkdir origin, got %q", entry.Meta.Origin.Kind)
+	}
+
+	// The metadata directory itself is not an experiment
+	entries, err := LoadEntries(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected 1 entry, got %d", len(entries))
+	}
+}
+
+// useConfig points the process config at a file with content.
+func useConfig(t *testing.T, content string) {
+	t.Helper()
+	t.Cleanup(func() { _ = config.Init(nil) })
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRY_CONFIG", path)
+	if err := config.Init(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoots_Profiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	work := filepath.Join(tmpDir, "work")
+	oss := filepath.Join(tmpDir, "oss")
+	useConfig(t, `[profile.work]
+path = "`+work+`"
+projects = "/src/work"
+
+[profile.oss]
+path = "`+oss+`"
+`)
+
+	t.Setenv("TRY_PROFILE", "work")
+	if got := TriesPath(); got != work {
+		t.Errorf("TriesPath() = %s, expected %s", got, work)
+	}
+	if got := ProjectsPath(); got != "/src/work" {
+		t.Errorf("ProjectsPath() = %s, expected /src/work", got)
+	}
+
+	t.Setenv("TRY_PROFILE", "oss,work")
+	roots := Roots()
+	if len(roots) != 2 || roots[0].Path != oss || roots[1].Path != work {
+		t.Fatalf("unexpected roots: %+v", roots)
+	}
+	if roots[0].Projects != tmpDir {
+		t.Errorf("projects should default to parent of path, got %s", roots[0].Projects)
+	}
+	if got := RootOf(filepath.Join(work, "2024-01-15-redis")); got.Profile != "work" {
+		t.Errorf("RootOf = %+v, expected work", got)
+	}
+
+	for _, dir := range []string{filepath.Join(work, "2024-01-15-redis"), filepath.Join(oss, "2024-01-16-redis")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected entries from both roots, got %d", len(entries))
+	}
+	profiles := map[string]string{}
+	for _, e := range entries {
+		profiles[e.Name] = e.Profile
+	}
+	if profiles["2024-01-15-redis"] != "work" || profiles["2024-01-16-redis"] != "oss" {
+		t.Errorf("unexpected profiles: %v", profiles)
+	}
+
+	// A single root doesn't badge its entries
+	t.Setenv("TRY_PROFILE", "work")
+	entries, _ = LoadAll()
+	if len(entries) != 1 || entries[0].Profile != "" {
+		t.Errorf("expected one unbadged entry, got %+v", entries)
+	}
+}
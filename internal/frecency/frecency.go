@@ -0,0 +1,167 @@
+package frecency
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xpzouying/try/internal/meta"
+)
+
+// maxTotal caps the sum of all visit counts. Past it, counts are aged
+// down so old favourites eventually make room (like zoxide's _ZO_MAXAGE).
+const maxTotal = 1000
+
+// dedupeWindow keeps the selector's cd and the shell hook firing for the
+// same directory change from counting twice.
+const dedupeWindow = time.Minute
+
+// Visit is the visit history of one entry.
+type Visit struct {
+	Count float64   `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// Score returns the zoxide-style frecency: visit count weighted by how
+// recently the entry was last visited.
+func (v Visit) Score(now time.Time) float64 {
+	if v.Count == 0 {
+		return 0
+	}
+	age := now.Sub(v.Last)
+	switch {
+	case age < time.Hour:
+		return v.Count * 4
+	case age < 24*time.Hour:
+		return v.Count * 2
+	case age < 7*24*time.Hour:
+		return v.Count / 2
+	default:
+		return v.Count / 4
+	}
+}
+
+// Log is the visit history for a tries directory, keyed by entry name.
+type Log struct {
+	path   string
+	Visits map[string]Visit `json:"visits"`
+}
+
+// Path returns the visit log file for a tries root.
+func Path(root string) string {
+	return filepath.Join(root, meta.Dir, "visits.json")
+}
+
+// Load reads the visit log for root. A missing log is empty.
+func Load(root string) (*Log, error) {
+	l := &Log{path: Path(root), Visits: map[string]Visit{}}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, err
+	}
+	if l.Visits == nil {
+		l.Visits = map[string]Visit{}
+	}
+	return l, nil
+}
+
+// Get returns the visit history for name.
+func (l *Log) Get(name string) Visit {
+	return l.Visits[name]
+}
+
+// Record counts a visit to name at now.
+func (l *Log) Record(name string, now time.Time) {
+	v := l.Visits[name]
+	if v.Count > 0 && now.Sub(v.Last) < dedupeWindow {
+		v.Last = now
+		l.Visits[name] = v
+		return
+	}
+	v.Count++
+	v.Last = now
+	l.Visits[name] = v
+	l.age()
+}
+
+// Rename moves the history of oldName to newName.
+func (l *Log) Rename(oldName, newName string) {
+	if v, ok := l.Visits[oldName]; ok {
+		delete(l.Visits, oldName)
+		l.Visits[newName] = v
+	}
+}
+
+// Forget drops the history of name.
+func (l *Log) Forget(name string) {
+	delete(l.Visits, name)
+}
+
+// Save writes the log, replacing the file atomically. Each save writes
+// its own temporary file, so concurrent shell hooks can't clobber one
+// another's before the rename.
+func (l *Log) Save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(append(data, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp, l.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+// age scales all counts down once their total exceeds maxTotal and
+// drops entries that fall below one visit.
+func (l *Log) age() {
+	total := 0.0
+	for _, v := range l.Visits {
+		total += v.Count
+	}
+	if total <= maxTotal {
+		return
+	}
+	for name, v := range l.Visits {
+		v.Count *= 0.9
+		if v.Count < 1 {
+			delete(l.Visits, name)
+			continue
+		}
+		l.Visits[name] = v
+	}
+}
+
+// Update loads the log for root, applies fn and saves it.
+func Update(root string, fn func(l *Log)) error {
+	l, err := Load(root)
+	if err != nil {
+		return err
+	}
+	fn(l)
+	return l.Save()
+}
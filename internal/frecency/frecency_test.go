@@ -0,0 +1,142 @@
+package frecency
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVisit_Score(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		last     time.Duration
+		expected float64
+	}{
+		{10 * time.Minute, 40},
+		{5 * time.Hour, 20},
+		{3 * 24 * time.Hour, 5},
+		{30 * 24 * time.Hour, 2.5},
+	}
+
+	for _, tc := range tests {
+		v := Visit{Count: 10, Last: now.Add(-tc.last)}
+		if got := v.Score(now); got != tc.expected {
+			t.Errorf("Score with last visit %v ago = %v, expected %v", tc.last, got, tc.expected)
+		}
+	}
+
+	if got := (Visit{}).Score(now); got != 0 {
+		t.Errorf("unvisited entry should score 0, got %v", got)
+	}
+}
+
+func TestLog_RecordAndPersist(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+
+	l, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Record("2024-01-15-redis", now.Add(-time.Hour))
+	l.Record("2024-01-15-redis", now)
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := reloaded.Get("2024-01-15-redis")
+	if v.Count != 2 {
+		t.Errorf("expected 2 visits, got %v", v.Count)
+	}
+	if !v.Last.Equal(now) {
+		t.Errorf("expected last visit %v, got %v", now, v.Last)
+	}
+}
+
+func TestLog_RecordDedupes(t *testing.T) {
+	l, _ := Load(t.TempDir())
+	now := time.Now()
+
+	// The selector's cd and the shell hook fire for the same change
+	l.Record("redis", now)
+	l.Record("redis", now.Add(time.Second))
+
+	if v := l.Get("redis"); v.Count != 1 {
+		t.Errorf("expected duplicate visit to be ignored, got %v", v.Count)
+	}
+}
+
+func TestLog_Aging(t *testing.T) {
+	l, _ := Load(t.TempDir())
+	now := time.Now()
+	l.Visits["old"] = Visit{Count: 1, Last: now.Add(-48 * time.Hour)}
+	l.Visits["busy"] = Visit{Count: maxTotal, Last: now.Add(-48 * time.Hour)}
+
+	l.Record("new", now)
+
+	if _, ok := l.Visits["old"]; ok {
+		t.Error("entries aged below one visit should be dropped")
+	}
+	if v := l.Get("busy"); v.Count >= maxTotal {
+		t.Errorf("counts should be scaled down, got %v", v.Count)
+	}
+}
+
+func TestLog_RenameForget(t *testing.T) {
+	l, _ := Load(t.TempDir())
+	l.Record("old", time.Now())
+
+	l.Rename("old", "new")
+	if l.Get("old").Count != 0 || l.Get("new").Count != 1 {
+		t.Error("rename should move visit history")
+	}
+
+	l.Forget("new")
+	if l.Get("new").Count != 0 {
+		t.Error("forget should drop visit history")
+	}
+}
+
+func TestLog_ConcurrentSaves(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l, _ := Load(t.TempDir()) // Fresh log, saved to the shared path
+			l.path = Path(root)
+			l.Record(fmt.Sprintf("2024-01-15-exp%d", i), now)
+			errs <- l.Save()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Save() error = %v", err)
+		}
+	}
+
+	l, err := Load(root)
+	if err != nil || len(l.Visits) != 1 {
+		t.Errorf("expected one complete log, got %+v, %v", l, err)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(Path(root)), "*.tmp"))
+	if len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+	if info, err := os.Stat(Path(root)); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("expected a 0644 log, got %v, %v", info, err)
+	}
+}
@@ -0,0 +1,206 @@
+package gitstatus
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Status summarizes a git working tree.
+type Status struct {
+	Branch      string // Branch name, or "(detached)"
+	Dirty       int    // Tracked files with staged or unstaged changes
+	Untracked   int    // Untracked files
+	Ahead       int    // Commits not on the upstream
+	Behind      int    // Upstream commits not merged
+	HasUpstream bool
+}
+
+// Clean reports whether there is nothing to commit.
+func (s *Status) Clean() bool {
+	return s.Dirty == 0 && s.Untracked == 0
+}
+
+// Result is the outcome of loading one directory's status.
+type Result struct {
+	Dir    string
+	Status *Status // nil if Dir is not a git working tree
+	Err    error
+}
+
+// IsRepo reports whether dir is the top of a git working tree
+// (.git is a directory, or a file for worktrees).
+func IsRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// Get returns the status of the working tree at dir.
+func Get(dir string) (*Status, error) {
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain=v2", "--branch").Output()
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(out)), nil
+}
+
+// Tracked reports whether git tracks any file under path in the working
+// tree at dir.
+func Tracked(dir, path string) (bool, error) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false, err
+	}
+	out, err := exec.Command("git", "-C", dir, "ls-files", "-z", "--", rel).Output()
+	if err != nil {
+		return false, err
+	}
+	return len(out) > 0, nil
+}
+
+// Parse reads `git status --porcelain=v2 --branch` output.
+func Parse(output string) *Status {
+	s := &Status{}
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			s.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			s.HasUpstream = true
+		case strings.HasPrefix(line, "# branch.ab "):
+			// "# branch.ab +1 -2"
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				s.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				s.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			s.Dirty++
+		case strings.HasPrefix(line, "? "):
+			s.Untracked++
+		}
+	}
+	return s
+}
+
+// LoadAll loads the status of every git working tree in dirs using at
+// most workers concurrent git processes. Results arrive in completion
+// order; the channel is closed when all are done. It is buffered for
+// every result, so workers finish even if nobody reads.
+func LoadAll(dirs []string, workers int) <-chan Result {
+	results := make(chan Result, len(dirs))
+	jobs := make(chan string)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dir := range jobs {
+				if !IsRepo(dir) {
+					continue
+				}
+				status, err := Get(dir)
+				results <- Result{Dir: dir, Status: status, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, dir := range dirs {
+			jobs <- dir
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// Risks summarizes work that deleting a git working tree could lose.
+type Risks struct {
+	Dirty     int // Tracked files with uncommitted changes
+	Untracked int // Untracked files
+	Stashes   int // Stash entries; not counted for linked worktrees
+	Unpushed  int // Commits on HEAD that would become unreachable
+}
+
+// Any reports whether anything would be lost.
+func (r *Risks) Any() bool {
+	return r.Dirty+r.Untracked+r.Stashes+r.Unpushed > 0
+}
+
+// Warnings describes each risk, e.g. "2 changed files".
+func (r *Risks) Warnings() []string {
+	var w []string
+	add := func(n int, one, many string) {
+		switch {
+		case n == 1:
+			w = append(w, "1 "+one)
+		case n > 1:
+			w = append(w, strconv.Itoa(n)+" "+many)
+		}
+	}
+	add(r.Dirty, "changed file", "changed files")
+	add(r.Untracked, "untracked file", "untracked files")
+	add(r.Stashes, "stash", "stashes")
+	add(r.Unpushed, "commit not on any remote branch", "commits not on any remote branch")
+	return w
+}
+
+// CheckRisks inspects the working tree at dir. It returns nil without
+// error if dir is not a git working tree.
+func CheckRisks(dir string) (*Risks, error) {
+	if !IsRepo(dir) {
+		return nil, nil
+	}
+	status, err := Get(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &Risks{Dirty: status.Dirty, Untracked: status.Untracked}
+
+	// A linked worktree shares stashes and branches with its repository,
+	// and removing it loses neither: only commits that no other branch
+	// or remote reaches are at risk
+	linked := isLinkedWorktree(dir)
+	if !linked {
+		out, err := exec.Command("git", "-C", dir, "stash", "list").Output()
+		if err != nil {
+			return nil, err
+		}
+		r.Stashes = countLines(string(out))
+	}
+
+	args := []string{"-C", dir, "rev-list", "--count", "HEAD", "--not", "--remotes"}
+	if linked {
+		if status.Branch != "(detached)" {
+			args = append(args, "--exclude="+status.Branch)
+		}
+		args = append(args, "--branches")
+	}
+	// Fails without commits, when there is nothing to lose
+	out, err := exec.Command("git", args...).Output()
+	if err == nil {
+		r.Unpushed, _ = strconv.Atoi(strings.TrimSpace(string(out)))
+	}
+	return r, nil
+}
+
+// isLinkedWorktree reports whether dir is a linked worktree (.git is a file).
+func isLinkedWorktree(dir string) bool {
+	info, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+func countLines(s string) int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	return strings.Count(s, "\n") + 1
+}
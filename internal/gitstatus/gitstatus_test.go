@@ -0,0 +1,183 @@
+package gitstatus
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	output := `# branch.oid 1234567890abcdef
+# branch.head main
+# branch.upstream origin/main
+# branch.ab +2 -1
+1 .M N... 100644 100644 100644 abc def main.go
+1 M. N... 100644 100644 100644 abc def go.mod
+2 R. N... 100644 100644 100644 abc def R100 new.go	old.go
+? notes.txt
+? tmp/
+`
+	s := Parse(output)
+	expected := Status{Branch: "main", Dirty: 3, Untracked: 2, Ahead: 2, Behind: 1, HasUpstream: true}
+	if *s != expected {
+		t.Errorf("Parse() = %+v, expected %+v", *s, expected)
+	}
+	if s.Clean() {
+		t.Error("status with changes should not be clean")
+	}
+}
+
+func TestParse_CleanDetached(t *testing.T) {
+	s := Parse("# branch.oid 1234567\n# branch.head (detached)\n")
+	if s.Branch != "(detached)" || s.HasUpstream || !s.Clean() {
+		t.Errorf("unexpected status: %+v", s)
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir := t.TempDir()
+
+	repo := filepath.Join(tmpDir, "repo")
+	plain := filepath.Join(tmpDir, "plain")
+	for _, dir := range []string{repo, plain} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out, err := exec.Command("git", "-C", repo, "init", "-q", "-b", "trunk").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []Result
+	for r := range LoadAll([]string{plain, repo, filepath.Join(tmpDir, "missing")}, 2) {
+		results = append(results, r)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected only the repository, got %+v", results)
+	}
+	r := results[0]
+	if r.Err != nil || r.Dir != repo {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+	if r.Status.Branch != "trunk" || r.Status.Untracked != 1 || r.Status.Dirty != 0 {
+		t.Errorf("unexpected status: %+v", r.Status)
+	}
+}
+
+func TestCheckRisks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir := t.TempDir()
+	if r, err := CheckRisks(tmpDir); r != nil || err != nil {
+		t.Errorf("CheckRisks() on a plain directory = %+v, %v", r, err)
+	}
+
+	repo := filepath.Join(tmpDir, "repo")
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-C", repo, "-c", "user.name=try", "-c", "user.email=try@example.com"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+
+	// A fresh repository has nothing to lose
+	r, err := CheckRisks(repo)
+	if err != nil || r == nil || r.Any() {
+		t.Fatalf("CheckRisks() on an empty repository = %+v, %v", r, err)
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "one")
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "first")
+	write("a.txt", "two")
+	git("stash", "-q")
+	write("a.txt", "three")
+	write("b.txt", "new")
+
+	r, err = CheckRisks(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Risks{Dirty: 1, Untracked: 1, Stashes: 1, Unpushed: 1}
+	if *r != expected {
+		t.Errorf("CheckRisks() = %+v, expected %+v", *r, expected)
+	}
+	warnings := strings.Join(r.Warnings(), ", ")
+	if warnings != "1 changed file, 1 untracked file, 1 stash, 1 commit not on any remote branch" {
+		t.Errorf("Warnings() = %q", warnings)
+	}
+}
+
+func TestCheckRisks_Worktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	wt := filepath.Join(tmpDir, "wt")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-C", dir, "-c", "user.name=try", "-c", "user.email=try@example.com"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git(repo, "init", "-q")
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("one"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(repo, "add", "a.txt")
+	git(repo, "commit", "-q", "-m", "first")
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("two"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(repo, "stash", "-q")
+	git(repo, "worktree", "add", "-q", "--detach", wt)
+
+	// The repository's stash and local-only commits survive removing the worktree
+	r, err := CheckRisks(wt)
+	if err != nil || r == nil || r.Any() {
+		t.Fatalf("CheckRisks() on a fresh worktree = %+v, %v", r, err)
+	}
+
+	// A detached commit would become unreachable
+	git(wt, "commit", "-q", "--allow-empty", "-m", "detached")
+	if r, err = CheckRisks(wt); err != nil || r.Unpushed != 1 {
+		t.Errorf("CheckRisks() with a detached commit = %+v, %v", r, err)
+	}
+
+	// Commits on the worktree's own branch count; other branches don't protect them
+	git(wt, "switch", "-q", "-c", "feature")
+	git(wt, "commit", "-q", "--allow-empty", "-m", "feature")
+	if r, err = CheckRisks(wt); err != nil || r.Unpushed != 2 {
+		t.Errorf("CheckRisks() on the worktree's branch = %+v, %v", r, err)
+	}
+	git(repo, "branch", "backup", "feature")
+	if r, err = CheckRisks(wt); err != nil || r.Unpushed != 0 {
+		t.Errorf("CheckRisks() with the commits on another branch = %+v, %v", r, err)
+	}
+}
@@ -0,0 +1,95 @@
+package guard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Check returns an error unless path lies strictly inside one of roots,
+// so it is safe to delete or move. The parent of path is resolved
+// through symlinks, which catches escapes like root/link/file with link
+// pointing elsewhere; missing directories are allowed, for move
+// destinations. path itself may be a symlink, since removing or moving
+// one leaves its target alone. Roots are resolved too. The filesystem
+// root and $HOME are never accepted, either as path or as a root.
+func Check(path string, roots []string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("refusing to modify %s: not an absolute path", path)
+	}
+	clean := filepath.Clean(path)
+	real := filepath.Join(resolve(filepath.Dir(clean)), filepath.Base(clean))
+
+	home := ""
+	if h, err := os.UserHomeDir(); err == nil {
+		home = resolve(h)
+	}
+	if real == string(filepath.Separator) || real == home {
+		return fmt.Errorf("refusing to modify %s", real)
+	}
+
+	var unsafe []string
+	for _, root := range roots {
+		r := resolve(root)
+		if r == string(filepath.Separator) || r == home {
+			unsafe = append(unsafe, root)
+			continue
+		}
+		if real == r {
+			return fmt.Errorf("refusing to modify %s: it is a tries root", path)
+		}
+		if within(real, r) {
+			return nil
+		}
+	}
+	if len(unsafe) > 0 {
+		return fmt.Errorf("refusing to modify %s: tries root %s is / or $HOME", path, strings.Join(unsafe, ", "))
+	}
+	return fmt.Errorf("refusing to modify %s: outside the tries roots (%s)", path, strings.Join(roots, ", "))
+}
+
+// CheckSource returns an error if moving the directory at path would
+// take the filesystem root, $HOME or one of roots with it. It guards
+// sources outside the roots, such as a project being demoted; path is
+// resolved through symlinks.
+func CheckSource(path string, roots []string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("refusing to move %s: not an absolute path", path)
+	}
+	real := resolve(path)
+	if real == string(filepath.Separator) {
+		return fmt.Errorf("refusing to move %s", real)
+	}
+	if h, err := os.UserHomeDir(); err == nil {
+		if home := resolve(h); real == home || within(home, real) {
+			return fmt.Errorf("refusing to move %s: it is or contains $HOME", path)
+		}
+	}
+	for _, root := range roots {
+		if r := resolve(root); real == r || within(r, real) {
+			return fmt.Errorf("refusing to move %s: it is or contains tries root %s", path, root)
+		}
+	}
+	return nil
+}
+
+// resolve returns the real path of p. Missing trailing components are
+// kept as they are, resolving the deepest ancestor that exists.
+func resolve(p string) string {
+	p = filepath.Clean(p)
+	if real, err := filepath.EvalSymlinks(p); err == nil {
+		return real
+	}
+	parent := filepath.Dir(p)
+	if parent == p {
+		return p
+	}
+	return filepath.Join(resolve(parent), filepath.Base(p))
+}
+
+// within reports whether path is below dir.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,127 @@
+package guard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(tmpDir, "tries")
+	outside := filepath.Join(tmpDir, "outside")
+	for _, dir := range []string{filepath.Join(root, "2024-01-15-redis"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A graduated entry and a link escaping the root
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	// A root reached through a symlink
+	linkedRoot := filepath.Join(tmpDir, "linked")
+	if err := os.Symlink(root, linkedRoot); err != nil {
+		t.Fatal(err)
+	}
+
+	roots := []string{root}
+	allowed := []string{
+		filepath.Join(root, "2024-01-15-redis"),
+		filepath.Join(root, "2024-01-15-redis", "node_modules"),
+		filepath.Join(root, "escape"), // The link itself, not its target
+		filepath.Join(root, ".trash", "x"),
+		filepath.Join(root, "new-name"), // Rename destinations need not exist
+		filepath.Join(linkedRoot, "2024-01-15-redis"),
+	}
+	for _, p := range allowed {
+		if err := Check(p, roots); err != nil {
+			t.Errorf("Check(%s) error = %v", p, err)
+		}
+	}
+
+	refused := map[string]string{
+		root:                                  "tries root",
+		linkedRoot:                            "tries root",
+		outside:                               "outside",
+		filepath.Join(root, "..", "outside"):  "outside",
+		filepath.Join(root, "escape", "file"): "outside",
+		"relative/path":                       "absolute",
+		"/":                                   "refusing",
+	}
+	for p, msg := range refused {
+		err := Check(p, roots)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Check(%s) error = %v, expected %q", p, err, msg)
+		}
+	}
+}
+
+func TestCheck_UnsafeRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, "projects")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Check(dir, []string{home}); err == nil || !strings.Contains(err.Error(), "$HOME") {
+		t.Errorf("expected $HOME root refused, got %v", err)
+	}
+	if err := Check(dir, []string{"/"}); err == nil {
+		t.Error("expected / root refused")
+	}
+	if err := Check(home, []string{filepath.Dir(home)}); err == nil {
+		t.Error("expected $HOME itself refused")
+	}
+}
+
+func TestCheckSource(t *testing.T) {
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := filepath.Join(tmpDir, "home")
+	root := filepath.Join(home, "tries")
+	project := filepath.Join(home, "projects", "redis")
+	for _, dir := range []string{root, project} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", home)
+	linkedHome := filepath.Join(tmpDir, "linked")
+	if err := os.Symlink(home, linkedHome); err != nil {
+		t.Fatal(err)
+	}
+
+	roots := []string{root}
+	if err := CheckSource(project, roots); err != nil {
+		t.Errorf("CheckSource(%s) error = %v", project, err)
+	}
+
+	refused := map[string]string{
+		home:       "$HOME",
+		linkedHome: "$HOME",
+		tmpDir:     "$HOME",
+		root:       "tries root",
+		"/":        "refusing",
+		"relative": "absolute",
+	}
+	for p, msg := range refused {
+		err := CheckSource(p, roots)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("CheckSource(%s) error = %v, expected %q", p, err, msg)
+		}
+	}
+
+	// A root below the source, even when $HOME isn't
+	t.Setenv("HOME", filepath.Join(tmpDir, "elsewhere"))
+	if err := CheckSource(home, roots); err == nil || !strings.Contains(err.Error(), "tries root") {
+		t.Errorf("expected a directory containing a root refused, got %v", err)
+	}
+}
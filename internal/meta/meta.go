@@ -0,0 +1,166 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dir is the hidden directory under a tries root that holds try's own state.
+// LoadEntries skips dot-directories, so it never shows up as an experiment.
+const Dir = ".try"
+
+// Origin kinds
+const (
+	OriginMkdir    = "mkdir"
+	OriginClone    = "clone"
+	OriginWorktree = "worktree"
+	OriginTemplate = "template"
+)
+
+// Meta is what try records about an experiment beyond its directory name and mtime.
+type Meta struct {
+	Description string      `json:"description,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Pinned      bool        `json:"pinned,omitempty"`
+	Origin      Origin      `json:"origin"`
+	CreatedAt   time.Time   `json:"created_at"`
+	Graduated   *Graduation `json:"graduated,omitempty"`
+}
+
+// Origin describes where an experiment came from.
+type Origin struct {
+	Kind       string `json:"kind,omitempty"`        // OriginMkdir, OriginClone, ...
+	URL        string `json:"url,omitempty"`         // For clones: remote URL
+	SourceRepo string `json:"source_repo,omitempty"` // For worktrees: source repository path
+	Template   string `json:"template,omitempty"`    // For templates: template name
+}
+
+// Graduation records where an experiment was promoted to.
+type Graduation struct {
+	At      time.Time `json:"at"`
+	Path    string    `json:"path"`
+	GitInit bool      `json:"git_init,omitempty"` // try created the repository
+	Remote  string    `json:"remote,omitempty"`   // Bare repository added as origin
+}
+
+// New returns metadata for an experiment created now.
+func New(origin Origin) *Meta {
+	return &Meta{Origin: origin, CreatedAt: time.Now()}
+}
+
+// Path returns the metadata file for the experiment name under root.
+// Metadata lives outside the experiment so it never dirties a git checkout.
+func Path(root, name string) string {
+	return filepath.Join(root, Dir, "meta", name+".json")
+}
+
+// Load reads metadata for name. It returns nil without error if none was recorded.
+func Load(root, name string) (*Meta, error) {
+	data, err := os.ReadFile(Path(root, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var m Meta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse metadata for %s: %w", name, err)
+	}
+	return &m, nil
+}
+
+// Save writes metadata for name, replacing the file atomically.
+func Save(root, name string, m *Meta) error {
+	path := Path(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Move carries metadata along when an experiment is renamed.
+func Move(root, oldName, newName string) error {
+	err := os.Rename(Path(root, oldName), Path(root, newName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Remove deletes metadata for name, if any.
+func Remove(root, name string) error {
+	err := os.Remove(Path(root, name))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// NormalizeTag lowercases a tag and strips a leading '#'.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// HasTag reports whether the tag is set.
+func (m *Meta) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag sets a tag, keeping Tags sorted and unique.
+func (m *Meta) AddTag(tag string) {
+	tag = NormalizeTag(tag)
+	if tag == "" || m.HasTag(tag) {
+		return
+	}
+	m.Tags = append(m.Tags, tag)
+	sort.Strings(m.Tags)
+}
+
+// RemoveTag unsets a tag.
+func (m *Meta) RemoveTag(tag string) {
+	tag = NormalizeTag(tag)
+	kept := m.Tags[:0]
+	for _, t := range m.Tags {
+		if t != tag {
+			kept = append(kept, t)
+		}
+	}
+	m.Tags = kept
+	if len(m.Tags) == 0 {
+		m.Tags = nil
+	}
+}
+
+// EditTags applies tag edits: "+tag" and bare "tag" add, "-tag" removes.
+func (m *Meta) EditTags(edits []string) {
+	for _, edit := range edits {
+		switch {
+		case strings.HasPrefix(edit, "-"):
+			m.RemoveTag(edit[1:])
+		case strings.HasPrefix(edit, "+"):
+			m.AddTag(edit[1:])
+		default:
+			m.AddTag(edit)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package meta
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	m, err := Load(t.TempDir(), "2024-01-15-redis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != nil {
+		t.Errorf("expected nil metadata, got %+v", m)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	root := t.TempDir()
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	m := &Meta{
+		Description: "cluster failover test",
+		Tags:        []string{"db", "redis"},
+		Origin:      Origin{Kind: OriginClone, URL: "https://github.com/redis/redis"},
+		CreatedAt:   created,
+	}
+
+	if err := Save(root, "2024-01-15-redis", m); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(root, "2024-01-15-redis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Description != m.Description {
+		t.Errorf("expected description %q, got %q", m.Description, loaded.Description)
+	}
+	if len(loaded.Tags) != 2 || loaded.Tags[1] != "redis" {
+		t.Errorf("unexpected tags: %v", loaded.Tags)
+	}
+	if loaded.Origin != m.Origin {
+		t.Errorf("expected origin %+v, got %+v", m.Origin, loaded.Origin)
+	}
+	if !loaded.CreatedAt.Equal(created) {
+		t.Errorf("expected created %v, got %v", created, loaded.CreatedAt)
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	root := t.TempDir()
+	if err := Save(root, "x", &Meta{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Path(root, "x"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(root, "x"); err == nil {
+		t.Error("expected error for corrupt metadata")
+	}
+}
+
+func TestMove(t *testing.T) {
+	root := t.TempDir()
+	if err := Save(root, "old", New(Origin{Kind: OriginMkdir})); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Move(root, "old", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := Load(root, "old"); m != nil {
+		t.Error("old metadata should be gone")
+	}
+	if m, _ := Load(root, "new"); m == nil || m.Origin.Kind != OriginMkdir {
+		t.Errorf("expected metadata under new name, got %+v", m)
+	}
+
+	// Moving an entry without metadata is not an error
+	if err := Move(root, "missing", "other"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	root := t.TempDir()
+	if err := Save(root, "x", New(Origin{Kind: OriginMkdir})); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(root, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := Load(root, "x"); m != nil {
+		t.Error("metadata should be removed")
+	}
+	if err := Remove(root, "x"); err != nil {
+		t.Errorf("removing twice should not error: %v", err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	m := &Meta{}
+	m.AddTag("Go")
+	m.AddTag("#db")
+	m.AddTag("go") // duplicate after normalization
+	m.AddTag("")
+
+	if len(m.Tags) != 2 || m.Tags[0] != "db" || m.Tags[1] != "go" {
+		t.Errorf("expected sorted unique tags [db go], got %v", m.Tags)
+	}
+	if !m.HasTag("#GO") {
+		t.Error("HasTag should normalize its argument")
+	}
+
+	m.RemoveTag("db")
+	m.RemoveTag("missing")
+	if len(m.Tags) != 1 || m.Tags[0] != "go" {
+		t.Errorf("expected [go], got %v", m.Tags)
+	}
+	m.RemoveTag("go")
+	if m.Tags != nil {
+		t.Errorf("expected nil tags after removing all, got %v", m.Tags)
+	}
+}
+
+func TestEditTags(t *testing.T) {
+	m := &Meta{Tags: []string{"old", "keep"}}
+	m.EditTags([]string{"+go", "db", "-old", "-missing"})
+
+	if strings.Join(m.Tags, ",") != "db,go,keep" {
+		t.Errorf("expected [db go keep], got %v", m.Tags)
+	}
+}
@@ -0,0 +1,140 @@
+package preview
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Preview summarizes a directory for the selector's preview pane.
+type Preview struct {
+	Readme []string // First lines of the README, if any
+	Tree   []string // Shallow file tree, directories first
+	GitLog []string // Recent `git log --oneline`, for git entries
+}
+
+const (
+	readmeLines = 8
+	treeDepth   = 2
+	treeEntries = 12
+	logLines    = 5
+)
+
+// skipDirs are noisy directories left out of the tree.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"__pycache__":  true,
+}
+
+// Load reads the preview of the directory at path. Missing pieces are
+// left empty; it never fails.
+func Load(path string) *Preview {
+	return &Preview{
+		Readme: readme(path, readmeLines),
+		Tree:   tree(path, treeDepth, treeEntries),
+		GitLog: gitLog(path, logLines),
+	}
+}
+
+// readme returns the first n non-blank lines of the README in dir.
+func readme(dir string, n int) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	// Prefer README.md, then any README* file
+	name := ""
+	for _, e := range entries {
+		lower := strings.ToLower(e.Name())
+		if e.IsDir() || !strings.HasPrefix(lower, "readme") {
+			continue
+		}
+		if name == "" || lower == "readme.md" {
+			name = e.Name()
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() && len(lines) < n {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" {
+			continue
+		}
+		lines = append(lines, strings.ReplaceAll(line, "\t", "    "))
+	}
+	return lines
+}
+
+// tree lists dir up to depth levels deep, at most max lines, indenting
+// nested entries and marking directories with a trailing slash.
+func tree(dir string, depth, max int) []string {
+	var lines []string
+	truncated := false
+
+	var walk func(dir, indent string, level int)
+	walk = func(dir, indent string, level int) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].IsDir() && !entries[j].IsDir()
+		})
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), ".") || (e.IsDir() && skipDirs[e.Name()]) {
+				continue
+			}
+			if len(lines) == max {
+				truncated = true
+				return
+			}
+			if e.IsDir() {
+				lines = append(lines, indent+e.Name()+"/")
+				if level < depth {
+					walk(filepath.Join(dir, e.Name()), indent+"  ", level+1)
+				}
+			} else {
+				lines = append(lines, indent+e.Name())
+			}
+		}
+	}
+	walk(dir, "", 1)
+
+	if truncated {
+		lines = append(lines, "…")
+	}
+	return lines
+}
+
+// gitLog returns the last n commits of the repository at dir, one line each.
+func gitLog(dir string, n int) []string {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return nil
+	}
+	out, err := exec.Command("git", "-C", dir, "log", "--oneline", "--no-color", "--no-decorate", "-n", strconv.Itoa(n)).Output()
+	if err != nil {
+		return nil
+	}
+	text := strings.TrimSpace(string(out))
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,101 @@
+package preview
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadme(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "README.txt"), "ignored\n")
+	writeFile(t, filepath.Join(dir, "README.md"), "# Redis test\n\nTrying\tstreams.\n\n1\n2\n3\n4\n5\n6\n7\n")
+
+	got := readme(dir, 4)
+	expected := []string{"# Redis test", "Trying    streams.", "1", "2"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("readme() = %q, expected %q", got, expected)
+	}
+}
+
+func TestReadme_Missing(t *testing.T) {
+	if got := readme(t.TempDir(), 5); got != nil {
+		t.Errorf("expected nil, got %q", got)
+	}
+}
+
+func TestTree(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.go"), "")
+	writeFile(t, filepath.Join(dir, "cmd", "server", "main.go"), "")
+	writeFile(t, filepath.Join(dir, "cmd", "server", "deep", "x.go"), "")
+	writeFile(t, filepath.Join(dir, ".env"), "")
+	writeFile(t, filepath.Join(dir, "node_modules", "a.js"), "")
+
+	got := tree(dir, 2, 10)
+	expected := []string{"cmd/", "  server/", "main.go"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("tree() = %q, expected %q", got, expected)
+	}
+}
+
+func TestTree_Truncated(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c", "d"} {
+		writeFile(t, filepath.Join(dir, name), "")
+	}
+
+	got := tree(dir, 1, 2)
+	expected := []string{"a", "b", "…"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("tree() = %q, expected %q", got, expected)
+	}
+}
+
+func TestGitLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=try", "-c", "user.email=try@example.com", "commit", "-q", "--allow-empty", "-m", "first"},
+		{"-c", "user.name=try", "-c", "user.email=try@example.com", "commit", "-q", "--allow-empty", "-m", "second"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	got := gitLog(dir, 5)
+	if len(got) != 2 || !strings.HasSuffix(got[0], " second") || !strings.HasSuffix(got[1], " first") {
+		t.Errorf("gitLog() = %q", got)
+	}
+
+	if got := gitLog(t.TempDir(), 5); got != nil {
+		t.Errorf("expected nil outside a repository, got %q", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "README"), "notes\n")
+
+	p := Load(dir)
+	if !reflect.DeepEqual(p.Readme, []string{"notes"}) || !reflect.DeepEqual(p.Tree, []string{"README"}) || p.GitLog != nil {
+		t.Errorf("unexpected preview: %+v", p)
+	}
+}
@@ -0,0 +1,215 @@
+package rank
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xpzouying/try/internal/config"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/fuzzy"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+// Result is an entry with its relevance for a query.
+type Result struct {
+	Entry     *entry.Entry
+	Score     float64
+	Positions []int // Matched character positions in Entry.Name
+}
+
+// Weights control how ranking signals blend into one score:
+//
+//	score = Match*match + Recency*recency + Frecency*frecency + Pin*pinned
+//
+// Every signal is normalized to [0, 1]:
+//   - match:    fuzzy score relative to the best match for the query (0 with no query)
+//   - recency:  Entry.Score (mtime bucket plus date prefix) over its maximum
+//   - frecency: visit frecency f squashed as f/(f+10)
+//   - pinned:   1 if the entry is pinned
+//
+// Ties are broken by newer ModTime, then by name, so ordering is deterministic.
+type Weights struct {
+	Match    float64
+	Recency  float64
+	Frecency float64
+	Pin      float64
+}
+
+// DefaultWeights let a clearly better match win, while a recent or often
+// visited entry beats a stale one of similar match quality.
+var DefaultWeights = Weights{
+	Match:    1.0,
+	Recency:  0.5,
+	Frecency: 0.5,
+	Pin:      1.0,
+}
+
+// maxEntryScore is the highest Entry.Score: used today plus date prefix.
+const maxEntryScore = 110
+
+// frecencyHalf is the frecency that maps to 0.5.
+const frecencyHalf = 10
+
+// ParseWeights parses "match=1,recency=0.5" style overrides on top of base.
+func ParseWeights(s string, base Weights) (Weights, error) {
+	w := base
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return base, fmt.Errorf("invalid weight %q (want name=value)", part)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return base, fmt.Errorf("invalid weight %q: %w", part, err)
+		}
+		if err := w.Set(strings.TrimSpace(key), f); err != nil {
+			return base, err
+		}
+	}
+	return w, nil
+}
+
+// Set assigns the weight with the given name.
+func (w *Weights) Set(name string, value float64) error {
+	switch strings.ToLower(name) {
+	case "match":
+		w.Match = value
+	case "recency":
+		w.Recency = value
+	case "frecency":
+		w.Frecency = value
+	case "pin":
+		w.Pin = value
+	default:
+		return fmt.Errorf("unknown weight %q (supported: match, recency, frecency, pin)", name)
+	}
+	return nil
+}
+
+// LoadWeights returns the configured weights (rank.* keys, overridden by
+// TRY_RANK_WEIGHTS). An invalid value is reported and the defaults are used.
+func LoadWeights() (Weights, error) {
+	// Reject malformed or unknown names in the variable up front
+	if _, err := ParseWeights(os.Getenv("TRY_RANK_WEIGHTS"), DefaultWeights); err != nil {
+		return DefaultWeights, err
+	}
+
+	w := DefaultWeights
+	for _, name := range []string{"match", "recency", "frecency", "pin"} {
+		f, err := strconv.ParseFloat(config.Get("rank."+name), 64)
+		if err != nil {
+			return DefaultWeights, fmt.Errorf("invalid weight rank.%s: %w", name, err)
+		}
+		_ = w.Set(name, f)
+	}
+	return w, nil
+}
+
+// Query is a parsed search query. Words starting with '#' are tags that
+// restrict candidates; the remaining words are fuzzy-matched on the name.
+type Query struct {
+	Tags  []string
+	Terms []string
+}
+
+// ParseQuery splits a raw query such as "#go redis" into tags and terms.
+func ParseQuery(s string) Query {
+	var q Query
+	for _, word := range strings.Fields(s) {
+		if strings.HasPrefix(word, "#") {
+			if tag := meta.NormalizeTag(word); tag != "" {
+				q.Tags = append(q.Tags, tag)
+			}
+			continue
+		}
+		q.Terms = append(q.Terms, word)
+	}
+	return q
+}
+
+// Fuzzy returns the text matched against entry names.
+// Terms are concatenated so "redis cluster" still matches "redis-cluster".
+func (q Query) Fuzzy() string {
+	return strings.Join(q.Terms, "")
+}
+
+// Name returns the directory name suffix for creating a new entry from the query.
+func (q Query) Name() string {
+	return strings.Join(q.Terms, "-")
+}
+
+// Rank filters and orders entries for query. This is the single ranking
+// used by the selector and by headless modes like `try --filter`.
+//
+// Tag words drop entries lacking any of the tags. Remaining terms drop
+// entries whose name does not fuzzy-match. Survivors are ordered by the
+// weighted blend described on Weights.
+func Rank(entries []*entry.Entry, query string, now time.Time, w Weights) []Result {
+	q := ParseQuery(query)
+	if len(q.Tags) > 0 {
+		tagged := make([]*entry.Entry, 0, len(entries))
+		for _, e := range entries {
+			if e.HasTags(q.Tags) {
+				tagged = append(tagged, e)
+			}
+		}
+		entries = tagged
+	}
+
+	var results []Result
+	if text := q.Fuzzy(); text == "" {
+		results = make([]Result, len(entries))
+		for i, e := range entries {
+			results[i] = Result{Entry: e, Score: w.blend(e, 0, now)}
+		}
+	} else {
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name
+		}
+
+		// Search returns matches best first, so the first score normalizes the rest
+		matches := fuzzy.Search(text, names)
+		results = make([]Result, 0, len(matches))
+		for _, match := range matches {
+			e := entries[match.StartIndex]
+			results = append(results, Result{
+				Entry:     e,
+				Score:     w.blend(e, match.Score/matches[0].Score, now),
+				Positions: match.Positions,
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.Entry.ModTime.Equal(b.Entry.ModTime) {
+			return a.Entry.ModTime.After(b.Entry.ModTime)
+		}
+		return a.Entry.Name < b.Entry.Name
+	})
+	return results
+}
+
+// blend combines the normalized signals for e.
+func (w Weights) blend(e *entry.Entry, match float64, now time.Time) float64 {
+	recency := e.Score(now) / maxEntryScore
+	f := e.Frecency(now)
+	frecency := f / (f + frecencyHalf)
+	pinned := 0.0
+	if e.Pinned() {
+		pinned = 1
+	}
+	return w.Match*match + w.Recency*recency + w.Frecency*frecency + w.Pin*pinned
+}
@@ -0,0 +1,215 @@
+package rank
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/frecency"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+func testEntries(now time.Time) []*entry.Entry {
+	return []*entry.Entry{
+		{Name: "2024-01-15-redis-cluster", ModTime: now.Add(-time.Hour), HasDate: true},
+		{Name: "2024-01-10-postgres", ModTime: now.Add(-48 * time.Hour), HasDate: true},
+		{Name: "redis", ModTime: now.Add(-60 * 24 * time.Hour)},
+	}
+}
+
+func TestRank_NoMatch(t *testing.T) {
+	now := time.Now()
+	if results := Rank(testEntries(now), "zzz", now, DefaultWeights); len(results) != 0 {
+		t.Errorf("expected no matches, got %d", len(results))
+	}
+}
+
+func TestRank_Order(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		query    string
+		weights  Weights
+		entries  []*entry.Entry
+		expected []string
+	}{
+		{
+			name:  "empty query orders by recency",
+			query: "",
+			entries: []*entry.Entry{
+				{Name: "old", ModTime: now.Add(-60 * day)},
+				{Name: "today", ModTime: now.Add(-time.Hour)},
+				{Name: "week", ModTime: now.Add(-3 * day)},
+			},
+			expected: []string{"today", "week", "old"},
+		},
+		{
+			name:  "recent entry beats stale one of similar match",
+			query: "redis",
+			entries: []*entry.Entry{
+				{Name: "redis-old", ModTime: now.Add(-400 * day)},
+				{Name: "redis-cluster", ModTime: now.Add(-day - time.Hour)},
+			},
+			expected: []string{"redis-cluster", "redis-old"},
+		},
+		{
+			name:  "clearly better match beats recency",
+			query: "redis",
+			entries: []*entry.Entry{
+				{Name: "2024-01-01-read-the-docs-is", ModTime: now.Add(-time.Hour), HasDate: true},
+				{Name: "redis", ModTime: now.Add(-400 * day)},
+			},
+			expected: []string{"redis", "2024-01-01-read-the-docs-is"},
+		},
+		{
+			name:    "match only weights ignore recency",
+			query:   "redis",
+			weights: Weights{Match: 1},
+			entries: []*entry.Entry{
+				{Name: "redis-cluster", ModTime: now.Add(-time.Hour)},
+				{Name: "redis-old", ModTime: now.Add(-400 * day)},
+			},
+			expected: []string{"redis-old", "redis-cluster"},
+		},
+		{
+			name:  "frecency breaks ties",
+			query: "redis",
+			entries: []*entry.Entry{
+				{Name: "redis-a", ModTime: now},
+				{Name: "redis-b", ModTime: now, Visits: frecency.Visit{Count: 20, Last: now}},
+			},
+			expected: []string{"redis-b", "redis-a"},
+		},
+		{
+			name:  "pinned entry floats to the top",
+			query: "",
+			entries: []*entry.Entry{
+				{Name: "today", ModTime: now},
+				{Name: "pinned", ModTime: now.Add(-400 * day), Meta: &meta.Meta{Pinned: true}},
+			},
+			expected: []string{"pinned", "today"},
+		},
+		{
+			name:  "ties break by newer mtime then name",
+			query: "",
+			entries: []*entry.Entry{
+				{Name: "b", ModTime: now.Add(-2 * 60 * day)},
+				{Name: "c", ModTime: now.Add(-60 * day)},
+				{Name: "a", ModTime: now.Add(-2 * 60 * day)},
+			},
+			expected: []string{"c", "a", "b"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			weights := tc.weights
+			if weights == (Weights{}) {
+				weights = DefaultWeights
+			}
+			results := Rank(tc.entries, tc.query, now, weights)
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Entry.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRank_Positions(t *testing.T) {
+	now := time.Now()
+	results := Rank(testEntries(now), "redis", now, DefaultWeights)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(results))
+	}
+	for _, r := range results {
+		if len(r.Positions) != len("redis") {
+			t.Errorf("expected match positions for %s, got %v", r.Entry.Name, r.Positions)
+		}
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Weights
+		hasError bool
+	}{
+		{"", DefaultWeights, false},
+		{"match=2", Weights{Match: 2, Recency: 0.5, Frecency: 0.5, Pin: 1}, false},
+		{"match=2, recency=0 ,pin=3,frecency=1", Weights{Match: 2, Recency: 0, Frecency: 1, Pin: 3}, false},
+		{"match", DefaultWeights, true},
+		{"match=x", DefaultWeights, true},
+		{"size=1", DefaultWeights, true},
+	}
+
+	for _, tc := range tests {
+		w, err := ParseWeights(tc.input, DefaultWeights)
+		if tc.hasError != (err != nil) {
+			t.Errorf("ParseWeights(%q) error = %v, expected error: %v", tc.input, err, tc.hasError)
+		}
+		if w != tc.expected {
+			t.Errorf("ParseWeights(%q) = %+v, expected %+v", tc.input, w, tc.expected)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		tags  []string
+		fuzzy string
+		name  string
+	}{
+		{"redis", nil, "redis", "redis"},
+		{"#go redis", []string{"go"}, "redis", "redis"},
+		{"#Go #db", []string{"go", "db"}, "", ""},
+		{"redis cluster #db", []string{"db"}, "rediscluster", "redis-cluster"},
+		{"# redis", nil, "redis", "redis"},
+		{"", nil, "", ""},
+	}
+
+	for _, tc := range tests {
+		q := ParseQuery(tc.query)
+		if strings.Join(q.Tags, ",") != strings.Join(tc.tags, ",") {
+			t.Errorf("ParseQuery(%q) tags = %v, expected %v", tc.query, q.Tags, tc.tags)
+		}
+		if q.Fuzzy() != tc.fuzzy {
+			t.Errorf("ParseQuery(%q).Fuzzy() = %q, expected %q", tc.query, q.Fuzzy(), tc.fuzzy)
+		}
+		if q.Name() != tc.name {
+			t.Errorf("ParseQuery(%q).Name() = %q, expected %q", tc.query, q.Name(), tc.name)
+		}
+	}
+}
+
+func TestRank_TagFilter(t *testing.T) {
+	now := time.Now()
+	entries := testEntries(now)
+	entries[0].Meta = &meta.Meta{Tags: []string{"db", "redis"}}
+	entries[1].Meta = &meta.Meta{Tags: []string{"db"}}
+
+	// Tags alone keep every tagged entry in original order
+	results := Rank(entries, "#db", now, DefaultWeights)
+	if len(results) != 2 || results[0].Entry != entries[0] || results[1].Entry != entries[1] {
+		t.Errorf("expected both db entries, got %d", len(results))
+	}
+
+	// Tags restrict candidates before fuzzy matching
+	results = Rank(entries, "#db redis", now, DefaultWeights)
+	if len(results) != 1 || results[0].Entry != entries[0] {
+		t.Errorf("expected only the tagged redis entry, got %d", len(results))
+	}
+
+	// Multiple tags must all match
+	results = Rank(entries, "#db #redis", now, DefaultWeights)
+	if len(results) != 1 || results[0].Entry != entries[0] {
+		t.Errorf("expected only the entry with both tags, got %d", len(results))
+	}
+}
@@ -0,0 +1,50 @@
+package selector
+
+import (
+	"github.com/charmbracelet/bubbletea"
+)
+
+// archivedSelected reports whether the highlighted entry is archived.
+// Archived entries can only be restored, by selecting them.
+func (m model) archivedSelected() bool {
+	return !m.isCreateSelected() && len(m.filtered) > 0 && m.filtered[m.cursor].entry.Archived
+}
+
+// archiveSelected packs the highlighted entry away. Archiving is
+// undone with try restore, so it needs no confirmation.
+func (m model) archiveSelected() (tea.Model, tea.Cmd) {
+	if m.isCreateSelected() || len(m.filtered) == 0 {
+		return m, nil
+	}
+	selected := m.filtered[m.cursor].entry
+	m.result = &Result{
+		Action:     "archive",
+		Path:       selected.Path,
+		BaseName:   selected.Name,
+		IsWorktree: selected.IsWorktree,
+	}
+	return m, tea.Quit
+}
+
+// confirmBulkArchive archives every marked entry. Worktrees belong to
+// their source repository and can't be archived.
+func (m model) confirmBulkArchive() (tea.Model, tea.Cmd) {
+	result := &Result{Action: "archive"}
+	for _, e := range m.markedEntries() {
+		if e.IsWorktree {
+			m.dialogError = "Worktrees can't be archived: " + e.Name
+			return m, nil
+		}
+		if e.Graduated() {
+			m.dialogError = "Already graduated: " + e.Name
+			return m, nil
+		}
+		result.Bulk = append(result.Bulk, Result{
+			Action:   "archive",
+			Path:     e.Path,
+			BaseName: e.Name,
+		})
+	}
+	m.result = result
+	return m, tea.Quit
+}
@@ -0,0 +1,311 @@
+package selector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+// actionTag is the bulk form of describe: it edits the tags of every marked entry.
+const actionTag = "tag"
+
+// bulkListLimit caps how many affected entries the bulk dialog lists.
+const bulkListLimit = 10
+
+// toggleMark marks or unmarks the highlighted entry and moves down.
+func (m model) toggleMark() (tea.Model, tea.Cmd) {
+	if m.isCreateSelected() || len(m.filtered) == 0 || m.archivedSelected() {
+		return m, nil
+	}
+
+	path := m.filtered[m.cursor].entry.Path
+	if m.marked[path] {
+		delete(m.marked, path)
+	} else {
+		m.marked[path] = true
+	}
+
+	if m.cursor < len(m.filtered)-1 {
+		m.cursor++
+	}
+	return m, nil
+}
+
+// markedEntries returns the marked entries in list order, including
+// ones hidden by the current query.
+func (m model) markedEntries() []*entry.Entry {
+	var result []*entry.Entry
+	for _, e := range m.entries {
+		if m.marked[e.Path] {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// enterBulkMode opens the confirmation dialog for action on all marked entries.
+func (m model) enterBulkMode(action string) (tea.Model, tea.Cmd) {
+	m.mode = modeBulk
+	m.bulkAction = action
+	m.dialogInput = ""
+	m.dialogError = ""
+	if action == actionGraduate {
+		// Default folder: the projects directory of the first marked entry
+		m.dialogInput = entry.RootOf(m.markedEntries()[0].Path).Projects
+	}
+	m.dialogCursor = len(m.dialogInput)
+	if action == actionDelete {
+		return m, m.checkDeletes(m.markedPaths())
+	}
+	return m, nil
+}
+
+func (m model) markedPaths() []string {
+	var paths []string
+	for _, e := range m.markedEntries() {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func (m model) handleBulkKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.mode = modeList
+		m.dialogError = ""
+		return m, nil
+
+	case tea.KeyEnter:
+		switch m.bulkAction {
+		case actionDelete:
+			return m.confirmBulkDelete()
+		case actionGraduate:
+			return m.confirmBulkGraduate()
+		case actionTag:
+			return m.confirmBulkTag()
+		case actionArchive:
+			return m.confirmBulkArchive()
+		}
+		return m, nil
+	}
+
+	m.editDialogInput(msg)
+	return m, nil
+}
+
+func (m model) confirmBulkDelete() (tea.Model, tea.Cmd) {
+	word, ok := m.confirmWord(m.markedPaths(), discardWord)
+	if !ok {
+		m.dialogError = "Still checking for unsaved work…"
+		return m, nil
+	}
+	if strings.ToUpper(strings.TrimSpace(m.dialogInput)) != word {
+		m.dialogError = "Type " + word + " to confirm deletion"
+		return m, nil
+	}
+
+	result := &Result{Action: "delete"}
+	for _, e := range m.markedEntries() {
+		if err := entry.CheckContained(e.Path); err != nil {
+			m.dialogError = err.Error()
+			return m, nil
+		}
+		result.Bulk = append(result.Bulk, Result{
+			Action:     "delete",
+			Path:       e.Path,
+			BaseName:   e.Name,
+			IsWorktree: e.IsWorktree,
+		})
+	}
+	m.result = result
+	return m, tea.Quit
+}
+
+// confirmBulkGraduate moves every marked entry into one folder, each
+// under its name without the date prefix. Every entry is checked first,
+// so a bad one doesn't leave the others half graduated.
+func (m model) confirmBulkGraduate() (tea.Model, tea.Cmd) {
+	if strings.TrimSpace(m.dialogInput) == "" {
+		m.dialogError = "Folder cannot be empty"
+		return m, nil
+	}
+	marked := m.markedEntries()
+	// Relative folders are inside the projects directory, as for graduate
+	folder := expandPath(m.dialogInput, entry.RootOf(marked[0].Path).Projects)
+
+	if info, err := os.Stat(folder); err == nil && !info.IsDir() {
+		m.dialogError = "Not a directory: " + folder
+		return m, nil
+	}
+
+	gradInit, gradPush := graduateDefaults()
+	result := &Result{Action: "graduate", DestPath: folder}
+	seen := map[string]bool{}
+	for _, e := range marked {
+		if e.Graduated() {
+			m.dialogError = "Already graduated: " + e.Name
+			return m, nil
+		}
+		if err := entry.CheckContained(e.Path); err != nil {
+			m.dialogError = err.Error()
+			return m, nil
+		}
+		dest := filepath.Join(folder, e.BaseName)
+		if seen[dest] {
+			m.dialogError = fmt.Sprintf("Two entries would both move to %s", e.BaseName)
+			return m, nil
+		}
+		seen[dest] = true
+		if _, err := os.Lstat(dest); err == nil {
+			m.dialogError = fmt.Sprintf("Destination already exists: %s", dest)
+			return m, nil
+		}
+		gitInit, remote := bootstrapOptions(e, dest, gradInit, gradPush)
+		result.Bulk = append(result.Bulk, Result{
+			Action:   "graduate",
+			Path:     e.Path,
+			DestPath: dest,
+			BaseName: e.Name,
+			GitInit:  gitInit,
+			Remote:   remote,
+		})
+	}
+	m.result = result
+	return m, tea.Quit
+}
+
+// confirmBulkTag saves the tag edits right away; the selector stays open.
+func (m model) confirmBulkTag() (tea.Model, tea.Cmd) {
+	edits := strings.Fields(m.dialogInput)
+	if len(edits) == 0 {
+		m.dialogError = "Enter tags: +tag adds, -tag removes"
+		return m, nil
+	}
+
+	for _, e := range m.markedEntries() {
+		updated := meta.Meta{}
+		if e.Meta != nil {
+			updated = *e.Meta
+			updated.Tags = append([]string(nil), e.Meta.Tags...)
+		}
+		updated.EditTags(edits)
+
+		if err := meta.Save(filepath.Dir(e.Path), e.Name, &updated); err != nil {
+			m.dialogError = fmt.Sprintf("Save failed for %s: %v", e.Name, err)
+			return m, nil
+		}
+		e.Meta = &updated
+	}
+
+	// Tags affect #tag filtering, so refresh the list
+	m.marked = map[string]bool{}
+	m.mode = modeList
+	m.filter()
+	return m, nil
+}
+
+func (m model) viewBulkDialog() string {
+	var b strings.Builder
+	marked := m.markedEntries()
+
+	// Header
+	b.WriteString("  ")
+	switch m.bulkAction {
+	case actionDelete:
+		b.WriteString(deleteStyle.Render("🗑️  Delete"))
+	case actionGraduate:
+		b.WriteString(graduateStyle.Render("🚀 Graduate"))
+	case actionTag:
+		b.WriteString(describeStyle.Render("🏷  Tag"))
+	case actionArchive:
+		b.WriteString(archiveStyle.Render("📦 Archive"))
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf(" - %d Selected", len(marked))))
+	b.WriteString("\n")
+
+	// Separator
+	b.WriteString(m.separator())
+	b.WriteString("\n\n")
+
+	// Everything affected
+	for i, e := range marked {
+		if i == bulkListLimit {
+			b.WriteString("  ")
+			b.WriteString(metaStyle.Render(fmt.Sprintf("…and %d more", len(marked)-bulkListLimit)))
+			b.WriteString("\n")
+			break
+		}
+		b.WriteString("  ")
+		if e.IsWorktree {
+			b.WriteString(worktreeStyle.Render("🌳 "))
+		} else {
+			b.WriteString(folderStyle.Render("📁 "))
+		}
+		b.WriteString(nameStyle.Render(e.Name))
+		for _, tag := range e.Tags() {
+			b.WriteString(tagStyle.Render(" #" + tag))
+		}
+		b.WriteString("\n")
+		if m.bulkAction == actionDelete {
+			w, _ := m.deleteWarnings(e.Path)
+			renderWarnings(&b, w, "     ")
+		}
+	}
+	b.WriteString("\n")
+
+	// Warning and input field
+	b.WriteString("  ")
+	switch m.bulkAction {
+	case actionDelete:
+		b.WriteString(errorStyle.Render("⚠ This will move these directories to the trash (try trash restore undoes it)."))
+		word, ok := m.confirmWord(m.markedPaths(), discardWord)
+		switch {
+		case !ok:
+			b.WriteString("\n  ")
+			b.WriteString(metaStyle.Render("Checking for unsaved work…"))
+			word = "YES"
+		case word == discardWord:
+			b.WriteString("\n  ")
+			b.WriteString(errorStyle.Render("Unsaved work would be lost once the trash is emptied."))
+		}
+		b.WriteString("\n\n  ")
+		b.WriteString(promptStyle.Render("Type " + word + " to confirm: "))
+	case actionGraduate:
+		b.WriteString(metaStyle.Render("Each entry moves to <folder>/<name without date>, leaving a symlink behind."))
+		b.WriteString("\n\n  ")
+		b.WriteString(promptStyle.Render("Move into folder: "))
+	case actionTag:
+		b.WriteString(metaStyle.Render("+tag adds, -tag removes; a bare tag adds."))
+		b.WriteString("\n\n  ")
+		b.WriteString(promptStyle.Render("Tags: "))
+	case actionArchive:
+		b.WriteString(metaStyle.Render("Each entry is packed into .try/archive/<name>.tar.gz and removed; try restore brings it back."))
+	}
+	if m.bulkAction != actionArchive {
+		b.WriteString(m.renderDialogInput())
+	}
+	b.WriteString("\n")
+
+	// Error message
+	if m.dialogError != "" {
+		b.WriteString("\n  ")
+		b.WriteString(errorStyle.Render("⚠ " + m.dialogError))
+		b.WriteString("\n")
+	}
+
+	// Separator
+	b.WriteString("\n")
+	b.WriteString(m.separator())
+	b.WriteString("\n")
+
+	// Footer
+	b.WriteString("  ")
+	b.WriteString(helpStyle.Render("Enter Confirm  Esc Cancel"))
+
+	return b.String()
+}
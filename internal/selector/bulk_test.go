@@ -0,0 +1,228 @@
+package selector
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func markedNames(m model) []string {
+	var names []string
+	for _, e := range m.markedEntries() {
+		names = append(names, e.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestToggleMark(t *testing.T) {
+	m, _, _ := testModel(t, "2024-01-15-redis", "2024-01-16-rust")
+
+	// Space marks while the query is empty and moves down
+	m = press(t, m, space)
+	if len(m.marked) != 1 || m.cursor != 1 {
+		t.Fatalf("after space: marked %v, cursor %d", m.marked, m.cursor)
+	}
+	m = press(t, m, key(tea.KeyUp), space)
+	if len(m.marked) != 0 {
+		t.Errorf("space should unmark, got %v", m.marked)
+	}
+
+	// With a query, space is typed and Tab marks
+	m = press(t, m, typed("re"), space)
+	if m.query != "re " || len(m.marked) != 0 {
+		t.Errorf("space with a query: query %q, marked %v", m.query, m.marked)
+	}
+	m = press(t, m, key(tea.KeyBackspace))
+	m.cursor = 0 // Past the match is "Create new", which can't be marked
+	m = press(t, m, key(tea.KeyTab))
+	if got := markedNames(m); len(got) != 1 || got[0] != "2024-01-15-redis" {
+		t.Errorf("Tab should mark the match, got %v", got)
+	}
+
+	// The first Esc clears the marks, the second cancels
+	m = press(t, m, key(tea.KeyEsc))
+	if len(m.marked) != 0 || m.result != nil {
+		t.Errorf("first Esc: marked %v, result %+v", m.marked, m.result)
+	}
+	m = press(t, m, key(tea.KeyEsc))
+	if m.result == nil || m.result.Action != "cancel" {
+		t.Errorf("second Esc: result %+v", m.result)
+	}
+}
+
+func TestMarks_SurviveFiltering(t *testing.T) {
+	m, _, _ := testModel(t, "2024-01-15-redis", "2024-01-16-rust", "2024-01-17-go")
+	m = press(t, m, space, space)
+	before := markedNames(m)
+	if len(before) != 2 {
+		t.Fatalf("expected two marks, got %v", before)
+	}
+
+	// Filter down to an entry that may or may not be marked; the marks stay
+	m = press(t, m, typed("go"))
+	if len(m.filtered) != 1 {
+		t.Fatalf("expected one match for go, got %d", len(m.filtered))
+	}
+	if got := markedNames(m); strings.Join(got, ",") != strings.Join(before, ",") {
+		t.Errorf("marks changed by filtering: %v, expected %v", got, before)
+	}
+
+	// Bulk actions apply to hidden marked entries too
+	m = press(t, m, key(tea.KeyCtrlE))
+	if m.mode != modeBulk || m.bulkAction != actionTag {
+		t.Fatalf("expected the bulk tag dialog, got mode %v %q", m.mode, m.bulkAction)
+	}
+	if !strings.Contains(m.viewBulkDialog(), "2 Selected") {
+		t.Error("the dialog should count hidden marked entries")
+	}
+}
+
+func TestBulkDelete(t *testing.T) {
+	m, root, _ := testModel(t, "2024-01-15-redis", "2024-01-16-rust")
+	m = press(t, m, space, space, key(tea.KeyCtrlD))
+	if m.mode != modeBulk || m.bulkAction != actionDelete {
+		t.Fatalf("expected the bulk delete dialog, got mode %v %q", m.mode, m.bulkAction)
+	}
+
+	// Nothing is deleted while unsaved work is still being checked
+	m = press(t, m, typed("YES"), key(tea.KeyEnter))
+	if m.result != nil || !strings.Contains(m.dialogError, "Still checking") {
+		t.Fatalf("expected to wait for checks, got %+v, %q", m.result, m.dialogError)
+	}
+
+	redis := filepath.Join(root, "2024-01-15-redis")
+	rust := filepath.Join(root, "2024-01-16-rust")
+	m = press(t, m, warningsMsg{warnings: map[string][]string{redis: {"1 changed file"}, rust: nil}})
+	m = press(t, m, key(tea.KeyEnter))
+	if m.result != nil || !strings.Contains(m.dialogError, discardWord) {
+		t.Fatalf("lost work should require %s, got %+v, %q", discardWord, m.result, m.dialogError)
+	}
+
+	m.dialogInput, m.dialogCursor = "", 0
+	m = press(t, m, typed(strings.ToLower(discardWord)), key(tea.KeyEnter))
+	if m.result == nil || m.result.Action != "delete" || len(m.result.Bulk) != 2 {
+		t.Fatalf("expected a bulk delete result, got %+v", m.result)
+	}
+	for _, r := range m.result.Bulk {
+		if r.Action != "delete" || (r.Path != redis && r.Path != rust) {
+			t.Errorf("unexpected delete %+v", r)
+		}
+	}
+}
+
+func TestBulkGraduate(t *testing.T) {
+	m, _, projects := testModel(t, "2024-01-15-redis", "2024-01-16-rust")
+	m = press(t, m, space, space, key(tea.KeyCtrlG))
+	if m.mode != modeBulk || m.bulkAction != actionGraduate || m.dialogInput != projects {
+		t.Fatalf("expected the bulk graduate dialog into %s, got mode %v %q %q", projects, m.mode, m.bulkAction, m.dialogInput)
+	}
+
+	m = press(t, m, key(tea.KeyEnter))
+	if m.result == nil || m.result.Action != "graduate" || len(m.result.Bulk) != 2 {
+		t.Fatalf("expected a bulk graduate result, got %+v (%s)", m.result, m.dialogError)
+	}
+	for _, r := range m.result.Bulk {
+		if filepath.Dir(r.DestPath) != projects || !strings.HasSuffix(r.Path, "-"+filepath.Base(r.DestPath)) {
+			t.Errorf("unexpected graduate %+v", r)
+		}
+	}
+}
+
+func TestBulkGraduate_Refused(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		setup    func(t *testing.T, root, projects string)
+		folder   string // Typed after the default folder
+		expected string
+	}{
+		{
+			name:     "destination exists",
+			names:    []string{"2024-01-15-redis", "2024-01-16-rust"},
+			setup:    func(t *testing.T, _, projects string) { mkdir(t, filepath.Join(projects, "rust")) },
+			expected: "Destination already exists",
+		},
+		{
+			name:     "same name twice",
+			names:    []string{"2024-01-15-redis", "2024-01-16-redis"},
+			expected: "Two entries would both move to redis",
+		},
+		{
+			name:  "graduated entry",
+			names: []string{"2024-01-15-redis"},
+			setup: func(t *testing.T, root, projects string) {
+				mkdir(t, filepath.Join(projects, "rust"))
+				if err := os.Symlink(filepath.Join(projects, "rust"), filepath.Join(root, "2024-01-16-rust")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expected: "Already graduated",
+		},
+		{
+			name:  "outside the tries roots",
+			names: []string{"2024-01-15-redis", "2024-01-16-rust"},
+			setup: func(t *testing.T, root, _ string) {
+				t.Setenv("TRY_PATH", filepath.Join(filepath.Dir(root), "elsewhere"))
+			},
+			expected: "outside the tries roots",
+		},
+		{
+			name:  "folder is a file",
+			names: []string{"2024-01-15-redis", "2024-01-16-rust"},
+			setup: func(t *testing.T, _, projects string) {
+				if err := os.WriteFile(filepath.Join(projects, "file"), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			folder:   "/file",
+			expected: "Not a directory",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root, projects := testRoot(t, tc.names...)
+			if tc.setup != nil {
+				tc.setup(t, root, projects)
+			}
+			m := loadModel(t, root)
+			m = press(t, m, space, space, key(tea.KeyCtrlG))
+			m = press(t, m, typed(tc.folder), key(tea.KeyEnter))
+			if m.result != nil || !strings.Contains(m.dialogError, tc.expected) {
+				t.Errorf("expected %q, got result %+v, error %q", tc.expected, m.result, m.dialogError)
+			}
+		})
+	}
+}
+
+func TestBulkTag(t *testing.T) {
+	m, root, _ := testModel(t, "2024-01-15-redis", "2024-01-16-rust")
+	m = press(t, m, space, space, key(tea.KeyCtrlE))
+	m = press(t, m, typed("+db +cache"), key(tea.KeyEnter))
+	if m.mode != modeList || m.result != nil || len(m.marked) != 0 {
+		t.Fatalf("expected the list with no marks, got mode %v, result %+v, marked %v (%s)", m.mode, m.result, m.marked, m.dialogError)
+	}
+
+	m = press(t, m, typed("#cache"))
+	if len(m.filtered) != 2 {
+		t.Errorf("expected both entries tagged cache, got %d matches", len(m.filtered))
+	}
+
+	entries := loadModel(t, root).entries
+	for _, e := range entries {
+		if e.Meta == nil || strings.Join(e.Meta.Tags, ",") != "cache,db" {
+			t.Errorf("%s: tags not saved, got %+v", e.Name, e.Meta)
+		}
+	}
+}
+
+func mkdir(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
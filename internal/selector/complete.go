@@ -0,0 +1,182 @@
+package selector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/xpzouying/try/internal/entry"
+)
+
+// completionListLimit caps how many completions the graduate dialog lists.
+const completionListLimit = 8
+
+// dirCompletions returns the directories matching the last element of
+// input, each written as input would read completed, with a trailing
+// slash. Relative input is relative to base. Hidden directories only
+// match a prefix starting with a dot.
+func dirCompletions(input, base string) []string {
+	dirPart, prefix := "", input
+	if i := strings.LastIndex(input, "/"); i >= 0 {
+		dirPart, prefix = input[:i+1], input[i+1:]
+	}
+	dir := expandPath(dirPart, base)
+
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var result []string
+	for _, item := range items {
+		name := item.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		// Follow symlinks to directories
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			continue
+		}
+		result = append(result, dirPart+name+"/")
+	}
+	sort.Strings(result)
+	return result
+}
+
+// expandPath expands ~ in a typed path and makes it absolute, relative
+// to base.
+func expandPath(input, base string) string {
+	p := strings.TrimSpace(input)
+	if strings.HasPrefix(p, "~") {
+		home, _ := os.UserHomeDir()
+		p = filepath.Join(home, p[1:])
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(base, p)
+	}
+	return filepath.Clean(p)
+}
+
+// resolveDest returns where graduating to the typed input moves the
+// project. An existing directory written with a trailing slash, as Tab
+// completes it, means graduating into it under name.
+func resolveDest(input, base, name string) string {
+	dest := expandPath(input, base)
+	if strings.HasSuffix(strings.TrimSpace(input), "/") {
+		if info, err := os.Stat(dest); err == nil && info.IsDir() {
+			return filepath.Join(dest, name)
+		}
+	}
+	return dest
+}
+
+// validateDest explains why dest can't be graduated to, or returns "".
+func validateDest(input, dest string) string {
+	if strings.TrimSpace(input) == "" {
+		return "Destination cannot be empty"
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		return "Destination already exists"
+	}
+	info, err := os.Stat(filepath.Dir(dest))
+	if err != nil {
+		return "Parent directory does not exist"
+	}
+	if !info.IsDir() {
+		return "Not a directory: " + filepath.Dir(dest)
+	}
+	return ""
+}
+
+// projectsBase is where relative graduate destinations point: the
+// projects directory of the entry's root.
+func (m model) projectsBase() string {
+	return entry.RootOf(m.dialogEntry.Path).Projects
+}
+
+// refreshCompletions recomputes the dropdown for the current input.
+func (m *model) refreshCompletions() {
+	m.completions = dirCompletions(m.dialogInput, m.projectsBase())
+	m.completionIdx = -1
+}
+
+// completeDest handles Tab (or Shift-Tab, backwards) in the graduate
+// dialog: it first extends the input to the completions' common prefix,
+// then cycles through them. A single match is entered, listing its
+// subdirectories next.
+func (m model) completeDest(backward bool) (tea.Model, tea.Cmd) {
+	c := m.completions
+	if len(c) == 0 {
+		return m, nil
+	}
+
+	if m.completionIdx < 0 {
+		if p := commonPrefix(c); len(p) > len(m.dialogInput) {
+			m.dialogInput = p
+			m.dialogCursor = len(p)
+			m.dialogError = ""
+			m.refreshCompletions()
+			return m, nil
+		}
+	}
+
+	switch {
+	case backward && m.completionIdx <= 0:
+		m.completionIdx = len(c) - 1
+	case backward:
+		m.completionIdx--
+	default:
+		m.completionIdx = (m.completionIdx + 1) % len(c)
+	}
+	m.dialogInput = c[m.completionIdx]
+	m.dialogCursor = len(m.dialogInput)
+	m.dialogError = ""
+	return m, nil
+}
+
+// commonPrefix returns the longest prefix shared by all of s.
+func commonPrefix(s []string) string {
+	p := s[0]
+	for _, x := range s[1:] {
+		for !strings.HasPrefix(x, p) {
+			_, size := utf8.DecodeLastRuneInString(p)
+			p = p[:len(p)-size]
+		}
+	}
+	return p
+}
+
+// renderCompletions shows the validation of the typed destination and
+// the dropdown of matching directories.
+func (m model) renderCompletions(b *strings.Builder) {
+	dest := resolveDest(m.dialogInput, m.projectsBase(), m.dialogEntry.BaseName)
+	b.WriteString("  ")
+	if msg := validateDest(m.dialogInput, dest); msg != "" {
+		b.WriteString(errorStyle.Render("✗ " + msg))
+	} else {
+		b.WriteString(metaStyle.Render("✓ New folder " + shortenHome(dest)))
+	}
+	b.WriteString("\n")
+
+	for i, c := range m.completions {
+		if i == completionListLimit {
+			b.WriteString("    ")
+			b.WriteString(metaStyle.Render(fmt.Sprintf("…and %d more", len(m.completions)-completionListLimit)))
+			b.WriteString("\n")
+			break
+		}
+		name := filepath.Base(c) + "/"
+		b.WriteString("  ")
+		if i == m.completionIdx {
+			b.WriteString(arrowStyle.Render("→ "))
+			b.WriteString(nameStyle.Render(name))
+		} else {
+			b.WriteString("  ")
+			b.WriteString(folderStyle.Render(name))
+		}
+		b.WriteString("\n")
+	}
+}
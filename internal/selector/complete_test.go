@@ -0,0 +1,123 @@
+package selector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirCompletions(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"redis", "redis-cache", "rust", ".config", "go/src"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(base, "readme.md"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", base)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"no match", "python", nil},
+		{"single match", "ru", []string{"rust/"}},
+		{"multiple matches", "re", []string{"redis-cache/", "redis/"}},
+		{"empty lists all visible directories", "", []string{"go/", "redis-cache/", "redis/", "rust/"}},
+		{"hidden with a dot", ".c", []string{".config/"}},
+		{"subdirectory", "go/", []string{"go/src/"}},
+		{"absolute", base + "/ru", []string{base + "/rust/"}},
+		{"home", "~/go/s", []string{"~/go/src/"}},
+		{"missing directory", "nope/", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := dirCompletions(tc.input, base); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("dirCompletions(%q) = %q, expected %q", tc.input, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		in       []string
+		expected string
+	}{
+		{[]string{"rust/"}, "rust/"},
+		{[]string{"redis/", "redis-cache/"}, "redis"},
+		{[]string{"go/", "rust/"}, ""},
+		{[]string{"café/", "cafè/"}, "caf"}, // Never splits a rune
+	}
+	for _, tc := range tests {
+		if got := commonPrefix(tc.in); got != tc.expected {
+			t.Errorf("commonPrefix(%q) = %q, expected %q", tc.in, got, tc.expected)
+		}
+	}
+}
+
+func TestResolveDest(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	base := filepath.Join(home, "projects")
+	if err := os.MkdirAll(filepath.Join(base, "work"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"relative", "redis", filepath.Join(base, "redis")},
+		{"absolute", "/srv/redis", "/srv/redis"},
+		{"home", "~/code/redis", filepath.Join(home, "code", "redis")},
+		{"bare home", "~", home},
+		{"cleaned", " work/../redis ", filepath.Join(base, "redis")},
+		{"into an existing directory", "work/", filepath.Join(base, "work", "redis")},
+		{"existing directory without slash", "work", filepath.Join(base, "work")},
+		{"new directory with slash", "fresh/", filepath.Join(base, "fresh")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveDest(tc.input, base, "redis"); got != tc.expected {
+				t.Errorf("resolveDest(%q) = %q, expected %q", tc.input, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidateDest(t *testing.T) {
+	base := t.TempDir()
+	if err := os.Mkdir(filepath.Join(base, "taken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"valid", "redis", ""},
+		{"empty", "  ", "Destination cannot be empty"},
+		{"exists", "taken", "Destination already exists"},
+		{"into existing", "taken/", ""},
+		{"missing parent", "missing/redis", "Parent directory does not exist"},
+		{"parent is a file", "file/redis", "Not a directory: " + filepath.Join(base, "file")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := resolveDest(tc.input, base, "redis")
+			if got := validateDest(tc.input, dest); got != tc.expected {
+				t.Errorf("validateDest(%q) = %q, expected %q", tc.input, got, tc.expected)
+			}
+		})
+	}
+}
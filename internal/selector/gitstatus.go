@@ -0,0 +1,65 @@
+package selector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/xpzouying/try/internal/gitstatus"
+)
+
+// gitStatusWorkers bounds how many git processes run at once.
+const gitStatusWorkers = 8
+
+// gitStatusMsg delivers one entry's git status and carries the channel
+// the remaining results arrive on.
+type gitStatusMsg struct {
+	result  gitstatus.Result
+	results <-chan gitstatus.Result
+}
+
+// loadGitStatus computes the git status of every entry in the background.
+// Results stream in one message at a time so badges appear as they are ready.
+func (m model) loadGitStatus() tea.Cmd {
+	paths := make([]string, len(m.entries))
+	for i, e := range m.entries {
+		paths[i] = e.Path
+	}
+	return func() tea.Msg {
+		return nextGitStatus(gitstatus.LoadAll(paths, gitStatusWorkers))()
+	}
+}
+
+// nextGitStatus waits for the next result; it yields nothing once all are in.
+func nextGitStatus(results <-chan gitstatus.Result) tea.Cmd {
+	return func() tea.Msg {
+		r, ok := <-results
+		if !ok {
+			return nil
+		}
+		return gitStatusMsg{result: r, results: results}
+	}
+}
+
+// renderGitStatus returns compact badges: branch, then ✓ when clean or
+// ●N changed and ?N untracked files, then ↑N/↓N against the upstream.
+func renderGitStatus(s *gitstatus.Status) string {
+	var b strings.Builder
+	b.WriteString(sourceStyle.Render("  ⎇ " + s.Branch))
+	if s.Clean() {
+		b.WriteString(gitCleanStyle.Render(" ✓"))
+	}
+	if s.Dirty > 0 {
+		b.WriteString(gitDirtyStyle.Render(fmt.Sprintf(" ●%d", s.Dirty)))
+	}
+	if s.Untracked > 0 {
+		b.WriteString(gitDirtyStyle.Render(fmt.Sprintf(" ?%d", s.Untracked)))
+	}
+	if s.Ahead > 0 {
+		b.WriteString(gitSyncStyle.Render(fmt.Sprintf(" ↑%d", s.Ahead)))
+	}
+	if s.Behind > 0 {
+		b.WriteString(gitSyncStyle.Render(fmt.Sprintf(" ↓%d", s.Behind)))
+	}
+	return b.String()
+}
@@ -0,0 +1,177 @@
+package selector
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/xpzouying/try/internal/config"
+	"github.com/xpzouying/try/internal/entry"
+)
+
+// graduatedSelected reports whether the highlighted entry is a symlink
+// left by graduate. It can't be archived; graduate demotes it instead.
+func (m model) graduatedSelected() bool {
+	return !m.isCreateSelected() && len(m.filtered) > 0 && m.filtered[m.cursor].entry.Graduated()
+}
+
+// renderTarget shows where a graduated entry points, flagging broken links.
+func renderTarget(e *entry.Entry) string {
+	target := shortenHome(e.Target)
+	if e.Broken {
+		return brokenStyle.Render("  ✗ broken → " + target)
+	}
+	return targetStyle.Render("  → " + target)
+}
+
+// shortenHome abbreviates the home directory to ~.
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
+
+// enterDemoteMode asks to move a graduated project back into the tries
+// directory under the symlink's name. Broken links have nothing to move.
+func (m model) enterDemoteMode() (tea.Model, tea.Cmd) {
+	selected := m.filtered[m.cursor].entry
+	if selected.Broken {
+		return m, nil
+	}
+	m.mode = modeDemote
+	m.dialogEntry = selected
+	m.dialogError = ""
+	return m, nil
+}
+
+func (m model) handleDemoteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.mode = modeList
+		return m, nil
+
+	case tea.KeyEnter:
+		m.result = &Result{
+			Action:   "demote",
+			Path:     m.dialogEntry.Target,
+			DestPath: m.dialogEntry.Path,
+			BaseName: m.dialogEntry.Name,
+		}
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m model) viewDemoteDialog() string {
+	var b strings.Builder
+
+	// Header
+	b.WriteString("  ")
+	b.WriteString(graduateStyle.Render("↩ Demote"))
+	b.WriteString(titleStyle.Render(" - Back to Tries"))
+	b.WriteString("\n")
+
+	// Separator
+	b.WriteString(m.separator())
+	b.WriteString("\n\n")
+
+	// Project and where it goes
+	b.WriteString("  ")
+	b.WriteString(folderStyle.Render("📁 "))
+	b.WriteString(nameStyle.Render(shortenHome(m.dialogEntry.Target)))
+	b.WriteString("\n\n  ")
+	b.WriteString(promptStyle.Render("Move back to: "))
+	b.WriteString(inputStyle.Render(m.dialogEntry.Name))
+	b.WriteString("\n\n  ")
+	b.WriteString(metaStyle.Render("The symlink is replaced by the project itself"))
+	b.WriteString("\n")
+
+	// Separator
+	b.WriteString("\n")
+	b.WriteString(m.separator())
+	b.WriteString("\n")
+
+	// Footer
+	b.WriteString("  ")
+	b.WriteString(helpStyle.Render("Enter Confirm  Esc Cancel"))
+
+	return b.String()
+}
+
+// graduateDefaults returns the configured bootstrapping: git init
+// (graduate.git_init) and pushing to a bare remote (graduate.remote set).
+func graduateDefaults() (gitInit, push bool) {
+	gitInit, _ = strconv.ParseBool(config.Get("graduate.git_init"))
+	return gitInit, config.Get("graduate.remote") != ""
+}
+
+// bootstrapOptions returns what graduating e to dest sets up: git init
+// unless it is a repository already, and a bare remote under
+// graduate.remote. Worktrees keep their source repository's remotes.
+func bootstrapOptions(e *entry.Entry, dest string, gitInit, push bool) (bool, string) {
+	isRepo := false
+	if _, err := os.Lstat(filepath.Join(e.Path, ".git")); err == nil {
+		isRepo = true
+	}
+	gitInit = gitInit && !isRepo
+
+	dir := config.Get("graduate.remote")
+	if !push || dir == "" || e.IsWorktree || (!isRepo && !gitInit) {
+		return gitInit, ""
+	}
+	if strings.HasPrefix(dir, "~") {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, dir[1:])
+	}
+	return gitInit, filepath.Join(dir, filepath.Base(dest)+".git")
+}
+
+// renderBootstrap lists the graduate dialog's bootstrapping toggles.
+func (m model) renderBootstrap(b *strings.Builder) {
+	check := func(on bool) string {
+		if on {
+			return "[x] "
+		}
+		return "[ ] "
+	}
+	dest := strings.TrimSpace(m.dialogInput)
+	gitInit, remote := bootstrapOptions(m.dialogEntry, dest, m.gradInit, m.gradPush)
+
+	b.WriteString("\n  ")
+	b.WriteString(promptStyle.Render("Ctrl-T "))
+	if _, err := os.Lstat(filepath.Join(m.dialogEntry.Path, ".git")); err == nil {
+		b.WriteString(metaStyle.Render("[-] git init (already a repository)"))
+	} else {
+		b.WriteString(inputStyle.Render(check(gitInit) + "git init and initial commit"))
+	}
+	b.WriteString("\n")
+
+	dir := config.Get("graduate.remote")
+	if dir == "" {
+		return
+	}
+	b.WriteString("  ")
+	b.WriteString(promptStyle.Render("Ctrl-O "))
+	switch {
+	case m.dialogEntry.IsWorktree:
+		b.WriteString(metaStyle.Render("[-] push (worktrees keep their source's remotes)"))
+	case m.gradPush && remote == "":
+		b.WriteString(metaStyle.Render("[-] push (needs a repository: enable git init)"))
+	default:
+		if remote == "" {
+			remote = filepath.Join(dir, filepath.Base(dest)+".git")
+		}
+		b.WriteString(inputStyle.Render(check(m.gradPush) + "push to " + shortenHome(remote)))
+	}
+	b.WriteString("\n")
+}
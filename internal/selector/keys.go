@@ -0,0 +1,112 @@
+package selector
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/xpzouying/try/internal/config"
+)
+
+// Selector actions that can be rebound with keys.<action> in the config file.
+const (
+	actionNew      = "new"
+	actionGraduate = "graduate"
+	actionDelete   = "delete"
+	actionRename   = "rename"
+	actionDescribe = "describe"
+	actionPreview  = "preview"
+	actionSort     = "sort"
+	actionArchive  = "archive"
+)
+
+// keyActions lists rebindable actions in footer order, with their labels.
+var keyActions = []struct{ action, label string }{
+	{actionNew, "New"},
+	{actionGraduate, "Graduate"},
+	{actionDelete, "Delete"},
+	{actionRename, "Rename"},
+	{actionDescribe, "Describe"},
+	{actionPreview, "Preview"},
+	{actionSort, "Size"},
+	{actionArchive, "Archive"},
+}
+
+// reservedKeys drive navigation and can't be rebound.
+var reservedKeys = map[string]bool{
+	"ctrl+c": true, "esc": true, "enter": true, "backspace": true, "tab": true,
+	"up": true, "down": true, "ctrl+p": true, "ctrl+n": true,
+}
+
+// keyMap maps a key (as tea.KeyMsg.String() reports it) to an action.
+type keyMap map[string]string
+
+// loadKeyMap reads the keys.* bindings from the config.
+func loadKeyMap() (keyMap, error) {
+	km := keyMap{}
+	for _, ka := range keyActions {
+		key := strings.ToLower(config.Get("keys." + ka.action))
+		if reservedKeys[key] {
+			return nil, fmt.Errorf("keys.%s: %s is reserved for navigation", ka.action, key)
+		}
+		if r, size := utf8.DecodeRuneInString(key); size > 0 && size == len(key) && unicode.IsPrint(r) {
+			// A bare character would be typed into the query instead
+			return nil, fmt.Errorf("keys.%s: %q needs a modifier, e.g. ctrl+%s or alt+%s", ka.action, key, key, key)
+		}
+		if other, ok := km[key]; ok {
+			return nil, fmt.Errorf("keys.%s: %s is already bound to keys.%s", ka.action, key, other)
+		}
+		km[key] = ka.action
+	}
+	return km, nil
+}
+
+// help renders the bindings for the footer, e.g. "^T New  ^G Graduate".
+func (km keyMap) help() string {
+	var parts []string
+	for _, ka := range keyActions {
+		for key, action := range km {
+			if action == ka.action {
+				parts = append(parts, keyLabel(key)+" "+ka.label)
+			}
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// bulkHelp renders the bindings that apply to marked entries.
+func (km keyMap) bulkHelp() string {
+	labels := map[string]string{actionDelete: "Delete", actionGraduate: "Graduate", actionDescribe: "Tag", actionArchive: "Archive"}
+	var parts []string
+	for _, ka := range keyActions {
+		label, ok := labels[ka.action]
+		if !ok {
+			continue
+		}
+		for key, action := range km {
+			if action == ka.action {
+				parts = append(parts, keyLabel(key)+" "+label)
+			}
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// keyLabel shortens "ctrl+t" to "^T".
+func keyLabel(key string) string {
+	if rest, ok := strings.CutPrefix(key, "ctrl+"); ok {
+		return "^" + strings.ToUpper(rest)
+	}
+	return key
+}
+
+// applyTheme adjusts styles for the ui.theme setting.
+func applyTheme(theme string) {
+	if theme == "mono" {
+		// Keep bold and reverse video, drop all colors
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
@@ -0,0 +1,46 @@
+package selector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xpzouying/try/internal/config"
+)
+
+func TestLoadKeyMap(t *testing.T) {
+	t.Cleanup(func() { _ = config.Init(nil) })
+
+	tests := []struct {
+		name     string
+		bindings []string
+		expected string // Error substring; empty means valid
+	}{
+		{"defaults", nil, ""},
+		{"rebound", []string{"keys.new=alt+n", "keys.sort=F2"}, ""},
+		{"bare letter", []string{"keys.new=n"}, `keys.new: "n" needs a modifier`},
+		{"bare space", []string{"keys.delete= "}, `keys.delete: " " needs a modifier`},
+		{"bare non-ASCII", []string{"keys.rename=é"}, `keys.rename: "é" needs a modifier`},
+		{"reserved", []string{"keys.new=tab"}, "keys.new: tab is reserved"},
+		{"duplicate", []string{"keys.new=ctrl+g"}, "keys.graduate: ctrl+g is already bound to keys.new"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := config.Init(tc.bindings); err != nil {
+				t.Fatal(err)
+			}
+			km, err := loadKeyMap()
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(km) != len(keyActions) {
+					t.Errorf("expected %d bindings, got %v", len(keyActions), km)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
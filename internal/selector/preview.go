@@ -0,0 +1,113 @@
+package selector
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xpzouying/try/internal/preview"
+)
+
+// previewMsg delivers a preview loaded in the background.
+type previewMsg struct {
+	path    string
+	preview *preview.Preview
+}
+
+// sidePreviewWidth is the terminal width from which the preview pane
+// sits beside the list instead of below it.
+const sidePreviewWidth = 100
+
+// loadPreview starts loading the highlighted entry's preview unless it
+// is hidden, cached or already loading. Loading runs off the UI loop so
+// navigation never waits on disk or git.
+func (m model) loadPreview() tea.Cmd {
+	if !m.showPreview || m.isCreateSelected() || len(m.filtered) == 0 || m.archivedSelected() {
+		return nil
+	}
+	if m.filtered[m.cursor].entry.Broken {
+		return nil
+	}
+	path := m.filtered[m.cursor].entry.Path
+	if _, ok := m.previews[path]; ok {
+		return nil
+	}
+	m.previews[path] = nil // Loading
+	return func() tea.Msg {
+		return previewMsg{path: path, preview: preview.Load(path)}
+	}
+}
+
+// renderPreview returns exactly height lines describing the highlighted
+// entry, each at most width columns wide.
+func (m model) renderPreview(width, height int) []string {
+	var lines []string
+	section := func(title string, body []string) {
+		if len(body) == 0 {
+			return
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, previewTitleStyle.Render(truncate(title, width)))
+		for _, line := range body {
+			lines = append(lines, previewStyle.Render(truncate("  "+line, width)))
+		}
+	}
+
+	switch {
+	case m.isCreateSelected() || len(m.filtered) == 0:
+		lines = append(lines, metaStyle.Render("No preview"))
+	case m.archivedSelected():
+		lines = append(lines, metaStyle.Render("Archived; Enter restores it"))
+	case m.filtered[m.cursor].entry.Broken:
+		target := shortenHome(m.filtered[m.cursor].entry.Target)
+		lines = append(lines,
+			errorStyle.Render(truncate("Broken link: "+target+" is gone", width)),
+			metaStyle.Render(truncate("Moved or removed since graduating; Ctrl-D removes the link", width)))
+	default:
+		p, ok := m.previews[m.filtered[m.cursor].entry.Path]
+		switch {
+		case !ok || p == nil:
+			lines = append(lines, metaStyle.Render("Loading preview…"))
+		case len(p.Readme)+len(p.Tree)+len(p.GitLog) == 0:
+			lines = append(lines, metaStyle.Render("Empty directory"))
+		default:
+			section("README", p.Readme)
+			section("Files", p.Tree)
+			section("Git log", p.GitLog)
+		}
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// joinPreview places the preview pane beside the list lines, or below
+// them on narrow terminals.
+func (m model) joinPreview(list []string, height int) []string {
+	if m.width >= sidePreviewWidth {
+		listWidth := m.width * 3 / 5
+		pane := m.renderPreview(m.width-listWidth-3, height)
+		clip := lipgloss.NewStyle().MaxWidth(listWidth)
+		out := make([]string, height)
+		for i := range out {
+			line := clip.Render(list[i])
+			pad := max(0, listWidth-lipgloss.Width(line))
+			out[i] = line + strings.Repeat(" ", pad) + separatorStyle.Render(" │ ") + pane[i]
+		}
+		return out
+	}
+
+	out := append([]string(nil), list...)
+	out = append(out, m.separator())
+	for _, line := range m.renderPreview(m.width-4, height-len(list)-1) {
+		out = append(out, "  "+line)
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/xpzouying/try/internal/config"
+	"github.com/xpzouying/try/internal/du"
+)
+
+// errCancelled is returned by a progress report after Ctrl-C.
+var errCancelled = errors.New("cancelled")
+
+// progressInterval throttles redraws while work reports progress.
+const progressInterval = 100 * time.Millisecond
+
+// progressBarWidth is the width of the bar in cells.
+const progressBarWidth = 30
+
+type progressMsg struct{ done, total int64 }
+
+type progressDoneMsg struct{}
+
+type progressModel struct {
+	title      string
+	done       int64
+	total      int64
+	cancelled  bool
+	cancelWork func()
+}
+
+// RunProgress runs work while drawing its progress on the terminal, for
+// slow operations such as graduating across filesystems. Ctrl-C makes the
+// next report return an error, which work should pass back to cancel.
+// Without a terminal, work runs without a display.
+func RunProgress(title string, work func(report func(done, total int64) error) error) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return work(func(int64, int64) error { return nil })
+	}
+	defer func() { _ = tty.Close() }()
+	applyTheme(config.Get("ui.theme"))
+
+	cancelled := make(chan struct{})
+	m := progressModel{title: title, cancelWork: func() { close(cancelled) }}
+	p := tea.NewProgram(m, tea.WithInput(tty), tea.WithOutput(tty))
+
+	result := make(chan error, 1)
+	go func() {
+		var last time.Time
+		err := work(func(done, total int64) error {
+			select {
+			case <-cancelled:
+				return errCancelled
+			default:
+			}
+			if now := time.Now(); done == total || now.Sub(last) >= progressInterval {
+				last = now
+				p.Send(progressMsg{done, total})
+			}
+			return nil
+		})
+		result <- err
+		p.Send(progressDoneMsg{})
+	}()
+
+	// Wait for the work even if the display fails: its result is what counts
+	_, _ = p.Run()
+	return <-result
+}
+
+func (m progressModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC && !m.cancelled {
+			m.cancelled = true
+			m.cancelWork()
+		}
+	case progressMsg:
+		m.done, m.total = msg.done, msg.total
+	case progressDoneMsg:
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m progressModel) View() string {
+	var b strings.Builder
+	b.WriteString("  ")
+	b.WriteString(graduateStyle.Render(m.title))
+	b.WriteString("\n  ")
+
+	filled := 0
+	if m.total > 0 {
+		filled = int(m.done * progressBarWidth / m.total)
+	}
+	b.WriteString(graduateStyle.Render(strings.Repeat("█", filled)))
+	b.WriteString(separatorStyle.Render(strings.Repeat("░", progressBarWidth-filled)))
+	b.WriteString(metaStyle.Render(fmt.Sprintf("  %s / %s", du.Format(m.done), du.Format(m.total))))
+	b.WriteString("\n  ")
+	if m.cancelled {
+		b.WriteString(errorStyle.Render("Cancelling…"))
+	} else {
+		b.WriteString(helpStyle.Render("Ctrl-C Cancel"))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package selector
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/xpzouying/try/internal/gitstatus"
+)
+
+// discardWord confirms a bulk delete that would lose work.
+const discardWord = "DISCARD"
+
+// warningsMsg delivers what deleting each path would lose.
+type warningsMsg struct {
+	warnings map[string][]string
+}
+
+// checkDeletes inspects paths off the UI loop for uncommitted, stashed
+// or unpushed work. Earlier results are dropped first: the tree may have
+// changed since.
+func (m model) checkDeletes(paths []string) tea.Cmd {
+	for _, p := range paths {
+		delete(m.warnings, p)
+	}
+	return func() tea.Msg {
+		warnings := map[string][]string{}
+		for _, p := range paths {
+			// Deleting a graduated symlink leaves the project alone
+			if info, err := os.Lstat(p); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				warnings[p] = nil
+				continue
+			}
+			r, err := gitstatus.CheckRisks(p)
+			switch {
+			case err != nil:
+				warnings[p] = []string{"git state could not be checked: " + err.Error()}
+			case r != nil:
+				warnings[p] = r.Warnings()
+			default:
+				warnings[p] = nil
+			}
+		}
+		return warningsMsg{warnings: warnings}
+	}
+}
+
+// deleteWarnings returns what deleting path would lose, and whether the
+// check has finished.
+func (m model) deleteWarnings(path string) ([]string, bool) {
+	w, ok := m.warnings[path]
+	return w, ok
+}
+
+// confirmWord returns what must be typed to delete paths: YES, or the
+// entry's name (DISCARD for several) when work would be lost. ok is false
+// while checks are still running.
+func (m model) confirmWord(paths []string, name string) (word string, ok bool) {
+	word = "YES"
+	for _, p := range paths {
+		w, done := m.deleteWarnings(p)
+		if !done {
+			return "", false
+		}
+		if len(w) > 0 {
+			word = name
+		}
+	}
+	return word, true
+}
+
+// renderWarnings lists what would be lost, one indented line each.
+func renderWarnings(b *strings.Builder, warnings []string, indent string) {
+	for _, w := range warnings {
+		b.WriteString(indent)
+		b.WriteString(errorStyle.Render("• " + w))
+		b.WriteString("\n")
+	}
+}
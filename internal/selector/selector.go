@@ -1,36 +1,64 @@
 package selector
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/xpzouying/try/internal/config"
+	"github.com/xpzouying/try/internal/du"
 	"github.com/xpzouying/try/internal/entry"
-	"github.com/xpzouying/try/internal/fuzzy"
+	"github.com/xpzouying/try/internal/gitstatus"
+	"github.com/xpzouying/try/internal/meta"
+	"github.com/xpzouying/try/internal/preview"
+	"github.com/xpzouying/try/internal/rank"
 )
 
 // Result represents the outcome of the selector.
 type Result struct {
-	Action     string // "cd", "mkdir", "graduate", "delete", "rename", "worktree", "cancel"
+	Action     string // "cd", "mkdir", "graduate", "demote", "delete", "rename", "archive", "restore", "worktree", "cancel"
 	Path       string
-	DestPath   string // For graduate/rename: destination path
-	BaseName   string // For graduate/delete/rename: original directory name
-	NewName    string // For rename: new directory name
-	RepoPath   string // For worktree: source repository path
-	IsWorktree bool   // For delete: whether the entry is a git worktree
+	DestPath   string   // For graduate/rename/demote: destination path; for cd: a graduated entry's target
+	BaseName   string   // For graduate/delete/rename: original directory name
+	NewName    string   // For rename: new directory name
+	RepoPath   string   // For worktree: source repository path
+	IsWorktree bool     // For delete: whether the entry is a git worktree
+	Tags       []string // For mkdir: tags to record on the new entry
+	GitInit    bool     // For graduate: git init and commit the project
+	Remote     string   // For graduate: bare repository to add as origin and push to
+	Bulk       []Result // For bulk delete/graduate: one result per marked entry
 }
 
 // Run launches the interactive selector and returns the result.
 func Run(initialQuery string) (*Result, error) {
-	entries, err := entry.LoadEntries(entry.TriesPath())
+	entries, err := entry.LoadAll()
 	if err != nil {
 		return nil, fmt.Errorf("load entries: %w", err)
 	}
+	if show, _ := strconv.ParseBool(config.Get("ui.show_archived")); show {
+		archived, err := entry.LoadArchived()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, archived...)
+	}
+	return run(entries, initialQuery, false)
+}
+
+// RunMarked opens the selector over entries with all of them marked, so
+// bulk actions apply to whatever the user leaves selected.
+func RunMarked(entries []*entry.Entry) (*Result, error) {
+	return run(entries, "", true)
+}
 
+func run(entries []*entry.Entry, initialQuery string, markAll bool) (*Result, error) {
 	// Open /dev/tty directly for TUI input/output.
 	// This is necessary because shell wrapper captures stdout with $(...),
 	// so we need to bypass stdout and write directly to the terminal.
@@ -40,7 +68,29 @@ func Run(initialQuery string) (*Result, error) {
 	}
 	defer func() { _ = tty.Close() }()
 
-	m := newModel(entries, initialQuery)
+	weights, err := rank.LoadWeights()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := loadKeyMap()
+	if err != nil {
+		return nil, err
+	}
+	applyTheme(config.Get("ui.theme"))
+
+	showPreview, _ := strconv.ParseBool(config.Get("ui.preview"))
+
+	// Cancels background work such as disk usage once the selector exits
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := newModel(entries, initialQuery, weights, keys, showPreview)
+	m.ctx = ctx
+	if markAll {
+		for _, e := range entries {
+			m.marked[e.Path] = true
+		}
+	}
 	p := tea.NewProgram(m,
 		tea.WithAltScreen(),
 		tea.WithInput(tty),
@@ -63,6 +113,9 @@ const (
 	modeGraduate
 	modeDelete
 	modeRename
+	modeDescribe
+	modeBulk
+	modeDemote
 )
 
 type model struct {
@@ -75,6 +128,27 @@ type model struct {
 	result       *Result
 	now          time.Time
 	showCreate   bool // Whether to show "Create new" option
+	weights      rank.Weights
+	keys         keyMap
+	ctx          context.Context // Cancelled when the selector exits
+
+	// Multi-select
+	marked     map[string]bool // Marked entry paths
+	bulkAction string          // Action of the bulk dialog
+
+	// Preview pane
+	showPreview bool
+	previews    map[string]*preview.Preview // By path; nil while loading
+
+	// Git status badges, by path; absent until loaded or when not a repository
+	gitStatus map[string]*gitstatus.Status
+
+	// Delete safety checks: what deleting each path would lose; absent while checking
+	warnings map[string][]string
+
+	// Disk usage in bytes, by path; absent until measured
+	sizes    map[string]int64
+	sortSize bool // Order by size instead of rank
 
 	// Dialog mode
 	mode         mode
@@ -82,6 +156,14 @@ type model struct {
 	dialogCursor int    // Cursor position in dialog input
 	dialogError  string // Error message to display
 	dialogEntry  *entry.Entry // Entry being operated on
+
+	// Graduate bootstrapping, toggled in the graduate dialog
+	gradInit bool // git init and commit
+	gradPush bool // Push to a bare repository under graduate.remote
+
+	// Graduate destination completion
+	completions   []string // Directories matching the input
+	completionIdx int      // Completion Tab last picked, -1 for none
 }
 
 type filteredEntry struct {
@@ -90,10 +172,19 @@ type filteredEntry struct {
 	positions []int
 }
 
-func newModel(entries []*entry.Entry, query string) model {
+func newModel(entries []*entry.Entry, query string, weights rank.Weights, keys keyMap, showPreview bool) model {
 	m := model{
-		entries:    entries,
-		query:      query,
+		entries:     entries,
+		query:       query,
+		weights:     weights,
+		keys:        keys,
+		showPreview: showPreview,
+		previews:    map[string]*preview.Preview{},
+		gitStatus:   map[string]*gitstatus.Status{},
+		sizes:       map[string]int64{},
+		warnings:    map[string][]string{},
+		ctx:         context.Background(),
+		marked:      map[string]bool{},
 		width:      80,
 		height:     24,
 		now:        time.Now(),
@@ -104,36 +195,26 @@ func newModel(entries []*entry.Entry, query string) model {
 }
 
 func (m *model) filter() {
-	if m.query == "" {
-		m.filtered = make([]filteredEntry, len(m.entries))
-		for i, e := range m.entries {
-			m.filtered[i] = filteredEntry{
-				entry: e,
-				score: e.Score(m.now),
-			}
+	results := rank.Rank(m.entries, m.query, m.now, m.weights)
+	m.filtered = make([]filteredEntry, len(results))
+	for i, r := range results {
+		m.filtered[i] = filteredEntry{
+			entry:     r.Entry,
+			score:     r.Score,
+			positions: r.Positions,
 		}
-		m.showCreate = false
-		return
 	}
 
-	names := make([]string, len(m.entries))
-	for i, e := range m.entries {
-		names[i] = e.Name
+	if m.sortSize {
+		m.sortBySize()
 	}
 
-	matches := fuzzy.Search(m.query, names)
-	m.filtered = make([]filteredEntry, 0, len(matches))
-	for _, match := range matches {
-		m.filtered = append(m.filtered, filteredEntry{
-			entry:     m.entries[match.StartIndex],
-			score:     match.Score,
-			positions: match.Positions,
-		})
-	}
+	// Offer "Create new" only when the query names something (tags alone don't)
+	name := rank.ParseQuery(m.query).Name()
+	m.showCreate = name != ""
 
 	// Check if exact name already exists (don't show create option if so)
-	newName := fmt.Sprintf("%s-%s", m.now.Format("2006-01-02"), m.query)
-	m.showCreate = true
+	newName := fmt.Sprintf("%s-%s", entry.DatePrefix(m.now), name)
 	for _, fe := range m.filtered {
 		if fe.entry.Name == newName {
 			m.showCreate = false
@@ -164,7 +245,7 @@ func (m model) isCreateSelected() bool {
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.SetWindowTitle("try")
+	return tea.Batch(tea.SetWindowTitle("try"), m.loadPreview(), m.loadGitStatus(), m.loadSizes())
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -177,9 +258,42 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleDeleteKey(msg)
 		case modeRename:
 			return m.handleRenameKey(msg)
+		case modeDescribe:
+			return m.handleDescribeKey(msg)
+		case modeBulk:
+			return m.handleBulkKey(msg)
+		case modeDemote:
+			return m.handleDemoteKey(msg)
 		default:
-			return m.handleKey(msg)
+			next, cmd := m.handleKey(msg)
+			// Keep the preview following the highlighted entry
+			if nm, ok := next.(model); ok && nm.result == nil && nm.mode == modeList {
+				return nm, tea.Batch(cmd, nm.loadPreview())
+			}
+			return next, cmd
+		}
+	case previewMsg:
+		m.previews[msg.path] = msg.preview
+		return m, nil
+	case gitStatusMsg:
+		// A failed git call just leaves the entry without badges
+		if msg.result.Err == nil {
+			m.gitStatus[msg.result.Dir] = msg.result.Status
+		}
+		return m, nextGitStatus(msg.results)
+	case warningsMsg:
+		for path, w := range msg.warnings {
+			m.warnings[path] = w
+		}
+		return m, nil
+	case sizeMsg:
+		if msg.result.Err == nil {
+			m.sizes[msg.result.Path] = msg.result.Size
+			if m.sortSize {
+				m.sortBySize()
+			}
 		}
+		return m, nextSize(msg.results)
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -189,11 +303,80 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// With entries marked, actions apply to all of them
+	if len(m.marked) > 0 {
+		switch m.keys[msg.String()] {
+		case actionDelete:
+			return m.enterBulkMode(actionDelete)
+		case actionGraduate:
+			return m.enterBulkMode(actionGraduate)
+		case actionDescribe:
+			return m.enterBulkMode(actionTag)
+		case actionArchive:
+			return m.enterBulkMode(actionArchive)
+		case actionRename:
+			return m, nil
+		}
+	}
+
+	// Archived entries can only be restored
+	if m.archivedSelected() {
+		switch m.keys[msg.String()] {
+		case actionGraduate, actionDelete, actionRename, actionDescribe, actionArchive:
+			return m, nil
+		}
+	}
+
+	// Graduated entries already live elsewhere; graduate moves them back
+	if m.graduatedSelected() {
+		switch m.keys[msg.String()] {
+		case actionGraduate:
+			return m.enterDemoteMode()
+		case actionArchive:
+			return m, nil
+		}
+	}
+
+	// Rebindable actions take precedence over typing into the query
+	switch m.keys[msg.String()] {
+	case actionNew:
+		return m.createNew()
+	case actionGraduate:
+		return m.enterGraduateMode()
+	case actionDelete:
+		return m.enterDeleteMode()
+	case actionRename:
+		return m.enterRenameMode()
+	case actionDescribe:
+		return m.enterDescribeMode()
+	case actionPreview:
+		m.showPreview = !m.showPreview
+		return m, nil
+	case actionSort:
+		m.sortSize = !m.sortSize
+		m.filter()
+		return m, nil
+	case actionArchive:
+		return m.archiveSelected()
+	}
+
 	switch msg.Type {
-	case tea.KeyCtrlC, tea.KeyEsc:
+	case tea.KeyEsc:
+		// First Esc clears the selection
+		if len(m.marked) > 0 {
+			m.marked = map[string]bool{}
+			return m, nil
+		}
 		m.result = &Result{Action: "cancel"}
 		return m, tea.Quit
 
+	case tea.KeyCtrlC:
+		m.result = &Result{Action: "cancel"}
+		return m, tea.Quit
+
+	case tea.KeyTab:
+		return m.toggleMark()
+
 	case tea.KeyEnter:
 		return m.selectCurrent()
 
@@ -210,18 +393,6 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case tea.KeyCtrlT:
-		return m.createNew()
-
-	case tea.KeyCtrlG:
-		return m.enterGraduateMode()
-
-	case tea.KeyCtrlD:
-		return m.enterDeleteMode()
-
-	case tea.KeyCtrlR:
-		return m.enterRenameMode()
-
 	case tea.KeyBackspace:
 		if len(m.query) > 0 {
 			m.query = m.query[:len(m.query)-1]
@@ -229,7 +400,11 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case tea.KeyRunes:
+	case tea.KeyRunes, tea.KeySpace:
+		// Space marks while the query is empty, and types otherwise
+		if msg.Type == tea.KeySpace && m.query == "" {
+			return m.toggleMark()
+		}
 		m.query += string(msg.Runes)
 		m.filter()
 		return m, nil
@@ -248,25 +423,37 @@ func (m model) selectCurrent() (tea.Model, tea.Cmd) {
 	}
 
 	selected := m.filtered[m.cursor].entry
+	if selected.Archived {
+		m.result = &Result{Action: "restore", Path: selected.Path, BaseName: selected.Name}
+		return m, tea.Quit
+	}
+	if selected.Broken {
+		// Nothing to jump to; the preview explains
+		return m, nil
+	}
 	m.result = &Result{
-		Action: "cd",
-		Path:   selected.Path,
+		Action:   "cd",
+		Path:     selected.Path,
+		DestPath: selected.Target,
 	}
 	return m, tea.Quit
 }
 
 func (m model) createNew() (tea.Model, tea.Cmd) {
-	if m.query == "" {
+	q := rank.ParseQuery(m.query)
+	if q.Name() == "" {
 		m.result = &Result{Action: "cancel"}
 		return m, tea.Quit
 	}
 
-	name := fmt.Sprintf("%s-%s", m.now.Format("2006-01-02"), m.query)
+	name := fmt.Sprintf("%s-%s", entry.DatePrefix(m.now), q.Name())
 	path := filepath.Join(entry.TriesPath(), name)
 
+	// Tags in the query are applied to the new experiment
 	m.result = &Result{
 		Action: "mkdir",
 		Path:   path,
+		Tags:   q.Tags,
 	}
 	return m, tea.Quit
 }
@@ -279,19 +466,37 @@ func (m model) enterGraduateMode() (tea.Model, tea.Cmd) {
 
 	selected := m.filtered[m.cursor].entry
 
-	// Default destination: projects_dir/basename
-	destPath := filepath.Join(entry.ProjectsPath(), selected.BaseName)
+	// Default destination: projects_dir/basename of the entry's root
+	destPath := filepath.Join(entry.RootOf(selected.Path).Projects, selected.BaseName)
 
 	m.mode = modeGraduate
 	m.dialogEntry = selected
 	m.dialogInput = destPath
 	m.dialogCursor = len(destPath)
 	m.dialogError = ""
+	m.gradInit, m.gradPush = graduateDefaults()
+	m.refreshCompletions()
 
 	return m, nil
 }
 
 func (m model) handleGraduateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyTab, tea.KeyShiftTab:
+		return m.completeDest(msg.Type == tea.KeyShiftTab)
+	}
+
+	// Keep completions following the input
+	before := m.dialogInput
+	next, cmd := m.editGraduateInput(msg)
+	if nm, ok := next.(model); ok && nm.dialogInput != before {
+		nm.refreshCompletions()
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m model) editGraduateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEsc, tea.KeyCtrlC:
 		// Cancel graduate mode
@@ -303,6 +508,14 @@ func (m model) handleGraduateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Confirm graduate
 		return m.confirmGraduate()
 
+	case tea.KeyCtrlT:
+		m.gradInit = !m.gradInit
+		return m, nil
+
+	case tea.KeyCtrlO:
+		m.gradPush = !m.gradPush
+		return m, nil
+
 	case tea.KeyBackspace:
 		if m.dialogCursor > 0 {
 			m.dialogInput = m.dialogInput[:m.dialogCursor-1] + m.dialogInput[m.dialogCursor:]
@@ -358,37 +571,20 @@ func (m model) handleGraduateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) confirmGraduate() (tea.Model, tea.Cmd) {
-	dest := strings.TrimSpace(m.dialogInput)
-
-	if dest == "" {
-		m.dialogError = "Destination cannot be empty"
-		return m, nil
-	}
-
-	// Expand home directory
-	if strings.HasPrefix(dest, "~") {
-		home, _ := os.UserHomeDir()
-		dest = filepath.Join(home, dest[1:])
-	}
-
-	// Check if destination already exists
-	if _, err := os.Stat(dest); err == nil {
-		m.dialogError = "Destination already exists"
-		return m, nil
-	}
-
-	// Check if parent directory exists
-	parent := filepath.Dir(dest)
-	if _, err := os.Stat(parent); os.IsNotExist(err) {
-		m.dialogError = "Parent directory does not exist"
-		return m, nil
+	// Relative destinations are inside the projects directory
+	dest := resolveDest(m.dialogInput, m.projectsBase(), m.dialogEntry.BaseName)
+	if validateDest(m.dialogInput, dest) != "" {
+		return m, nil // Shown inline already
 	}
 
+	gitInit, remote := bootstrapOptions(m.dialogEntry, dest, m.gradInit, m.gradPush)
 	m.result = &Result{
 		Action:   "graduate",
 		Path:     m.dialogEntry.Path,
 		DestPath: dest,
 		BaseName: m.dialogEntry.Name,
+		GitInit:  gitInit,
+		Remote:   remote,
 	}
 	return m, tea.Quit
 }
@@ -423,7 +619,7 @@ func (m model) enterDeleteMode() (tea.Model, tea.Cmd) {
 	m.dialogCursor = 0
 	m.dialogError = ""
 
-	return m, nil
+	return m, m.checkDeletes([]string{selected.Path})
 }
 
 func (m model) handleDeleteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -458,8 +654,17 @@ func (m model) handleDeleteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) confirmDelete() (tea.Model, tea.Cmd) {
-	if strings.ToUpper(strings.TrimSpace(m.dialogInput)) != "YES" {
-		m.dialogError = "Type YES to confirm deletion"
+	word, ok := m.confirmWord([]string{m.dialogEntry.Path}, m.dialogEntry.Name)
+	if !ok {
+		m.dialogError = "Still checking for unsaved work…"
+		return m, nil
+	}
+	input := strings.TrimSpace(m.dialogInput)
+	if word == "YES" {
+		input = strings.ToUpper(input)
+	}
+	if input != word {
+		m.dialogError = "Type " + word + " to confirm deletion"
 		return m, nil
 	}
 
@@ -578,7 +783,7 @@ func (m model) confirmRename() (tea.Model, tea.Cmd) {
 	}
 
 	// Check if destination already exists
-	newPath := filepath.Join(entry.TriesPath(), newName)
+	newPath := filepath.Join(filepath.Dir(m.dialogEntry.Path), newName)
 	if _, err := os.Stat(newPath); err == nil {
 		m.dialogError = fmt.Sprintf("Directory exists: %s", newName)
 		return m, nil
@@ -594,6 +799,111 @@ func (m model) confirmRename() (tea.Model, tea.Cmd) {
 	return m, tea.Quit
 }
 
+func (m model) enterDescribeMode() (tea.Model, tea.Cmd) {
+	// Can only describe an existing directory
+	if m.isCreateSelected() || len(m.filtered) == 0 {
+		return m, nil
+	}
+
+	selected := m.filtered[m.cursor].entry
+
+	m.mode = modeDescribe
+	m.dialogEntry = selected
+	m.dialogInput = selected.Description()
+	m.dialogCursor = len(m.dialogInput)
+	m.dialogError = ""
+
+	return m, nil
+}
+
+func (m model) handleDescribeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		// Cancel describe mode
+		m.mode = modeList
+		m.dialogError = ""
+		return m, nil
+
+	case tea.KeyEnter:
+		// Save description
+		return m.confirmDescribe()
+	}
+
+	m.editDialogInput(msg)
+	return m, nil
+}
+
+// confirmDescribe saves the description right away; the selector stays open.
+func (m model) confirmDescribe() (tea.Model, tea.Cmd) {
+	e := m.dialogEntry
+	updated := meta.Meta{}
+	if e.Meta != nil {
+		updated = *e.Meta
+	}
+	updated.Description = strings.TrimSpace(m.dialogInput)
+
+	if err := meta.Save(filepath.Dir(e.Path), e.Name, &updated); err != nil {
+		m.dialogError = fmt.Sprintf("Save failed: %v", err)
+		return m, nil
+	}
+
+	e.Meta = &updated
+	m.mode = modeList
+	return m, nil
+}
+
+// editDialogInput applies line-editing keys to the dialog input.
+// The cursor is a byte offset kept on rune boundaries, so free text
+// such as descriptions can contain non-ASCII characters.
+func (m *model) editDialogInput(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if m.dialogCursor > 0 {
+			_, size := utf8.DecodeLastRuneInString(m.dialogInput[:m.dialogCursor])
+			m.dialogInput = m.dialogInput[:m.dialogCursor-size] + m.dialogInput[m.dialogCursor:]
+			m.dialogCursor -= size
+			m.dialogError = ""
+		}
+
+	case tea.KeyLeft, tea.KeyCtrlB:
+		if m.dialogCursor > 0 {
+			_, size := utf8.DecodeLastRuneInString(m.dialogInput[:m.dialogCursor])
+			m.dialogCursor -= size
+		}
+
+	case tea.KeyRight, tea.KeyCtrlF:
+		if m.dialogCursor < len(m.dialogInput) {
+			_, size := utf8.DecodeRuneInString(m.dialogInput[m.dialogCursor:])
+			m.dialogCursor += size
+		}
+
+	case tea.KeyCtrlA:
+		m.dialogCursor = 0
+
+	case tea.KeyCtrlE:
+		m.dialogCursor = len(m.dialogInput)
+
+	case tea.KeyCtrlK:
+		m.dialogInput = m.dialogInput[:m.dialogCursor]
+		m.dialogError = ""
+
+	case tea.KeyCtrlW:
+		// Delete word backward
+		if m.dialogCursor > 0 {
+			newPos := wordBoundaryBackward(m.dialogInput, m.dialogCursor)
+			m.dialogInput = m.dialogInput[:newPos] + m.dialogInput[m.dialogCursor:]
+			m.dialogCursor = newPos
+			m.dialogError = ""
+		}
+
+	case tea.KeyRunes, tea.KeySpace:
+		ch := string(msg.Runes)
+		m.dialogInput = m.dialogInput[:m.dialogCursor] + ch + m.dialogInput[m.dialogCursor:]
+		m.dialogCursor += len(ch)
+		m.dialogError = ""
+	}
+}
+
 // Styles - using vibrant colors similar to Ruby version
 var (
 	titleStyle = lipgloss.NewStyle().
@@ -665,6 +975,54 @@ var (
 
 	sourceStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("245")) // Gray for source repo
+
+	describeStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("141")) // Purple for describe
+
+	tagStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("37")) // Teal for tags
+
+	profileStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("179")) // Amber for profile badges
+
+	previewTitleStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("111")) // Light blue for preview sections
+
+	markStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("213")) // Pink for marked entries
+
+	previewStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("250"))
+
+	archiveStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("180")) // Tan for archive
+
+	archivedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Faint(true) // Dimmed archived entries
+
+	targetStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("208")) // Orange, like graduate
+
+	brokenStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")) // Red for broken links
+
+	gitCleanStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("42")) // Green for a clean tree
+
+	gitDirtyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")) // Orange for uncommitted changes
+
+	gitSyncStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("75")) // Blue for ahead/behind
+
+	descriptionStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("242")).
+				Italic(true)
 )
 
 func (m model) View() string {
@@ -675,6 +1033,12 @@ func (m model) View() string {
 		return m.viewDeleteDialog()
 	case modeRename:
 		return m.viewRenameDialog()
+	case modeDescribe:
+		return m.viewDescribeDialog()
+	case modeBulk:
+		return m.viewBulkDialog()
+	case modeDemote:
+		return m.viewDemoteDialog()
 	}
 
 	var b strings.Builder
@@ -683,6 +1047,15 @@ func (m model) View() string {
 	b.WriteString("  ")
 	b.WriteString(titleStyle.Render("🏠 Try"))
 	b.WriteString(titleStyle.Render(" - Experiment Directory"))
+	if profile := config.Get("core.profile"); profile != "" {
+		b.WriteString(profileStyle.Render(" [" + profile + "]"))
+	}
+	if n := len(m.marked); n > 0 {
+		b.WriteString(markStyle.Render(fmt.Sprintf("  %d selected", n)))
+	}
+	if m.sortSize {
+		b.WriteString(metaStyle.Render("  by size"))
+	}
 	b.WriteString("\n")
 
 	// Separator
@@ -706,37 +1079,60 @@ func (m model) View() string {
 		visibleCount = 3
 	}
 
+	var lines []string
+	if !m.showPreview {
+		lines = m.renderList(visibleCount)
+	} else if m.width >= sidePreviewWidth {
+		lines = m.joinPreview(m.renderList(visibleCount), visibleCount)
+	} else {
+		// Bottom pane takes about half the rows
+		listCount := max(3, visibleCount-visibleCount/2-1)
+		lines = m.joinPreview(m.renderList(listCount), visibleCount)
+	}
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	// Separator
+	b.WriteString(m.separator())
+	b.WriteString("\n")
+
+	// Footer
+	b.WriteString("  ")
+	if len(m.marked) > 0 {
+		b.WriteString(helpStyle.Render("Tab Mark  " + m.keys.bulkHelp() + "  Esc Clear"))
+	} else {
+		b.WriteString(helpStyle.Render("↑/↓  Enter  Tab Mark  " + m.keys.help() + "  Esc"))
+	}
+
+	return b.String()
+}
+
+// renderList returns exactly count lines of the visible list window.
+func (m model) renderList(count int) []string {
 	totalItems := m.totalItems()
 	start := 0
-	if m.cursor >= visibleCount {
-		start = m.cursor - visibleCount + 1
+	if m.cursor >= count {
+		start = m.cursor - count + 1
 	}
-	end := min(start+visibleCount, totalItems)
+	end := min(start+count, totalItems)
 
 	// Render directory entries
+	lines := make([]string, 0, count)
 	for i := start; i < end; i++ {
 		if i < len(m.filtered) {
-			b.WriteString(m.renderEntry(i, i == m.cursor))
+			lines = append(lines, m.renderEntry(i, i == m.cursor))
 		} else if m.showCreate {
-			b.WriteString(m.renderCreateOption(i == m.cursor))
+			lines = append(lines, m.renderCreateOption(i == m.cursor))
 		}
-		b.WriteString("\n")
 	}
 
 	// Fill empty lines
-	for i := totalItems; i < visibleCount; i++ {
-		b.WriteString("\n")
+	for len(lines) < count {
+		lines = append(lines, "")
 	}
-
-	// Separator
-	b.WriteString(m.separator())
-	b.WriteString("\n")
-
-	// Footer
-	b.WriteString("  ")
-	b.WriteString(helpStyle.Render("↑/↓  Enter  ^T New  ^G Graduate  ^D Delete  ^R Rename  Esc"))
-
-	return b.String()
+	return lines
 }
 
 func (m model) viewGraduateDialog() string {
@@ -759,11 +1155,14 @@ func (m model) viewGraduateDialog() string {
 	b.WriteString("\n\n")
 
 	// Destination hint
-	envHint := "$TRY_PROJECTS"
-	if os.Getenv("TRY_PROJECTS") == "" {
-		envHint = "parent of $TRY_PATH"
-	}
-	projectsDir := entry.ProjectsPath()
+	root := entry.RootOf(m.dialogEntry.Path)
+	envHint := "core.projects"
+	if root.Profile != "" {
+		envHint = "profile " + root.Profile
+	} else if _, source := config.Current().Resolve("core.projects"); source == config.SourceDefault {
+		envHint = "parent of core.path"
+	}
+	projectsDir := root.Projects
 	b.WriteString("  ")
 	b.WriteString(metaStyle.Render(fmt.Sprintf("Destination (%s: %s)", envHint, projectsDir)))
 	b.WriteString("\n\n")
@@ -780,13 +1179,20 @@ func (m model) viewGraduateDialog() string {
 		b.WriteString(cursorStyle.Render(string(m.dialogInput[m.dialogCursor])))
 		b.WriteString(inputStyle.Render(m.dialogInput[m.dialogCursor+1:]))
 	}
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+
+	// Inline validation and matching directories
+	m.renderCompletions(&b)
+	b.WriteString("\n")
 
 	// Symlink hint
 	b.WriteString("  ")
 	b.WriteString(metaStyle.Render("A symlink will be left in the tries directory"))
 	b.WriteString("\n")
 
+	// Bootstrapping options
+	m.renderBootstrap(&b)
+
 	// Error message
 	if m.dialogError != "" {
 		b.WriteString("\n  ")
@@ -801,7 +1207,7 @@ func (m model) viewGraduateDialog() string {
 
 	// Footer
 	b.WriteString("  ")
-	b.WriteString(helpStyle.Render("Enter Confirm  Esc Cancel"))
+	b.WriteString(helpStyle.Render("Tab Complete  Enter Confirm  Esc Cancel"))
 
 	return b.String()
 }
@@ -836,15 +1242,32 @@ func (m model) viewDeleteDialog() string {
 	// Warning
 	b.WriteString("  ")
 	if m.dialogEntry.IsWorktree {
-		b.WriteString(errorStyle.Render("⚠ This will remove the worktree and update the main repository."))
+		b.WriteString(errorStyle.Render("⚠ This will move the worktree to the trash; git keeps tracking it until the trash is emptied."))
+	} else if m.dialogEntry.Graduated() {
+		b.WriteString(errorStyle.Render("⚠ This will move the link to the trash; the project it points to is left alone."))
 	} else {
-		b.WriteString(errorStyle.Render("⚠ This will permanently delete the directory and all its contents!"))
+		b.WriteString(errorStyle.Render("⚠ This will move the directory to the trash (try trash restore undoes it)."))
 	}
 	b.WriteString("\n\n")
 
+	// Safety checks
+	word, ok := m.confirmWord([]string{m.dialogEntry.Path}, m.dialogEntry.Name)
+	if !ok {
+		b.WriteString("  ")
+		b.WriteString(metaStyle.Render("Checking for unsaved work…"))
+		b.WriteString("\n\n")
+		word = "YES"
+	} else if w, _ := m.deleteWarnings(m.dialogEntry.Path); len(w) > 0 {
+		b.WriteString("  ")
+		b.WriteString(errorStyle.Render("Unsaved work would be lost once the trash is emptied:"))
+		b.WriteString("\n")
+		renderWarnings(&b, w, "    ")
+		b.WriteString("\n")
+	}
+
 	// Input field
 	b.WriteString("  ")
-	b.WriteString(promptStyle.Render("Type YES to confirm: "))
+	b.WriteString(promptStyle.Render("Type " + word + " to confirm: "))
 	b.WriteString(inputStyle.Render(m.dialogInput))
 	b.WriteString(cursorStyle.Render("█"))
 	b.WriteString("\n")
@@ -921,6 +1344,74 @@ func (m model) viewRenameDialog() string {
 	return b.String()
 }
 
+func (m model) viewDescribeDialog() string {
+	var b strings.Builder
+
+	// Header
+	b.WriteString("  ")
+	b.WriteString(describeStyle.Render("📝 Describe"))
+	b.WriteString(titleStyle.Render(" - Experiment Notes"))
+	b.WriteString("\n")
+
+	// Separator
+	b.WriteString(m.separator())
+	b.WriteString("\n\n")
+
+	// Directory being described
+	b.WriteString("  ")
+	b.WriteString(folderStyle.Render("📁 "))
+	b.WriteString(nameStyle.Render(m.dialogEntry.Name))
+	b.WriteString("\n\n")
+
+	// Origin, if recorded
+	if md := m.dialogEntry.Meta; md != nil && md.Origin.Kind != "" {
+		origin := md.Origin.Kind
+		if md.Origin.URL != "" {
+			origin += " " + md.Origin.URL
+		} else if md.Origin.SourceRepo != "" {
+			origin += " " + md.Origin.SourceRepo
+		}
+		b.WriteString("  ")
+		b.WriteString(metaStyle.Render(fmt.Sprintf("Origin: %s, created %s", origin, md.CreatedAt.Format("2006-01-02 15:04"))))
+		b.WriteString("\n\n")
+	}
+
+	// Input field
+	b.WriteString("  ")
+	b.WriteString(promptStyle.Render("Description: "))
+	b.WriteString(m.renderDialogInput())
+	b.WriteString("\n")
+
+	// Error message
+	if m.dialogError != "" {
+		b.WriteString("\n  ")
+		b.WriteString(errorStyle.Render("⚠ " + m.dialogError))
+		b.WriteString("\n")
+	}
+
+	// Separator
+	b.WriteString("\n")
+	b.WriteString(m.separator())
+	b.WriteString("\n")
+
+	// Footer
+	b.WriteString("  ")
+	b.WriteString(helpStyle.Render("Enter Save  Esc Cancel"))
+
+	return b.String()
+}
+
+// renderDialogInput renders the dialog input with its cursor.
+func (m model) renderDialogInput() string {
+	if m.dialogCursor >= len(m.dialogInput) {
+		return inputStyle.Render(m.dialogInput) + cursorStyle.Render("█")
+	}
+	_, size := utf8.DecodeRuneInString(m.dialogInput[m.dialogCursor:])
+	return inputStyle.Render(m.dialogInput[:m.dialogCursor]) +
+		cursorStyle.Render(m.dialogInput[m.dialogCursor:m.dialogCursor+size]) +
+		inputStyle.Render(m.dialogInput[m.dialogCursor+size:])
+}
+
 func (m model) separator() string {
 	width := m.width - 2
 	if width < 10 {
@@ -933,32 +1424,55 @@ func (m model) renderEntry(idx int, selected bool) string {
 	fe := m.filtered[idx]
 	var line strings.Builder
 
-	// Selection indicator
+	// Selection indicator and mark
 	if selected {
-		line.WriteString(arrowStyle.Render("→ "))
+		line.WriteString(arrowStyle.Render("→"))
 	} else {
-		line.WriteString("  ")
+		line.WriteString(" ")
+	}
+	if m.marked[fe.entry.Path] {
+		line.WriteString(markStyle.Render("●"))
+	} else {
+		line.WriteString(" ")
+	}
+
+	// Pin marker
+	if fe.entry.Pinned() {
+		line.WriteString("📌")
 	}
 
-	// Folder emoji (🌳 for worktree, 📁 for regular)
-	if fe.entry.IsWorktree {
+	// Folder emoji (🌳 for worktree, 📦 for archived, 🚀 for graduated, 📁 for regular)
+	if fe.entry.Archived {
+		line.WriteString(archivedStyle.Render("📦 "))
+	} else if fe.entry.Graduated() {
+		line.WriteString(graduateStyle.Render("🚀 "))
+	} else if fe.entry.IsWorktree {
 		line.WriteString(worktreeStyle.Render("🌳 "))
 	} else {
 		line.WriteString(folderStyle.Render("📁 "))
 	}
 
-	// Directory name with date dimmed
+	// Root badge when several profiles are merged
+	if fe.entry.Profile != "" {
+		line.WriteString(profileStyle.Render("[" + fe.entry.Profile + "]"))
+		line.WriteString(" ")
+	}
+
+	// Directory name with date dimmed; archived entries are dimmed entirely
 	name := fe.entry.Name
-	if fe.entry.HasDate && len(name) > 11 {
-		datePart := name[:11]  // "2024-01-15-"
-		namePart := name[11:]  // rest
+	if fe.entry.Archived {
+		line.WriteString(archivedStyle.Render(name + " (archived)"))
+	} else if fe.entry.HasDate && fe.entry.BaseName != "" {
+		dateLen := len(name) - len(fe.entry.BaseName)
+		datePart := name[:dateLen] // "2024-01-15-"
+		namePart := name[dateLen:] // rest
 
 		// Render date part dimmed
 		line.WriteString(dateStyle.Render(datePart))
 
 		// Render name part with highlights
 		if len(fe.positions) > 0 {
-			line.WriteString(m.highlightName(namePart, fe.positions, 11))
+			line.WriteString(m.highlightName(namePart, fe.positions, dateLen))
 		} else {
 			line.WriteString(nameStyle.Render(namePart))
 		}
@@ -975,6 +1489,9 @@ func (m model) renderEntry(idx int, selected bool) string {
 	age := formatAge(fe.entry.ModTime)
 	scoreStr := fmt.Sprintf("%.1f", fe.score)
 	meta := fmt.Sprintf("  %s, %s", age, scoreStr)
+	if size, ok := m.sizes[fe.entry.Path]; ok {
+		meta += ", " + du.Format(size)
+	}
 	line.WriteString(metaStyle.Render(meta))
 
 	// Source repo for worktrees
@@ -982,6 +1499,26 @@ func (m model) renderEntry(idx int, selected bool) string {
 		line.WriteString(sourceStyle.Render(fmt.Sprintf("  ← %s", fe.entry.SourceRepo)))
 	}
 
+	// Target for graduated entries
+	if fe.entry.Graduated() {
+		line.WriteString(renderTarget(fe.entry))
+	}
+
+	// Git status badges
+	if s := m.gitStatus[fe.entry.Path]; s != nil {
+		line.WriteString(renderGitStatus(s))
+	}
+
+	// Tags
+	for _, tag := range fe.entry.Tags() {
+		line.WriteString(tagStyle.Render(" #" + tag))
+	}
+
+	// Description, truncated to keep the list on one line
+	if desc := fe.entry.Description(); desc != "" {
+		line.WriteString(descriptionStyle.Render("  " + truncate(desc, 40)))
+	}
+
 	// Apply selected background
 	result := line.String()
 	if selected {
@@ -1023,11 +1560,15 @@ func (m model) renderCreateOption(selected bool) string {
 
 	line.WriteString(createStyle.Render("📂 "))
 
-	datePrefix := m.now.Format("2006-01-02")
-	if m.query == "" {
+	datePrefix := entry.DatePrefix(m.now)
+	q := rank.ParseQuery(m.query)
+	if q.Name() == "" {
 		line.WriteString(createStyle.Render(fmt.Sprintf("Create new: %s-", datePrefix)))
 	} else {
-		line.WriteString(createStyle.Render(fmt.Sprintf("Create new: %s-%s", datePrefix, m.query)))
+		line.WriteString(createStyle.Render(fmt.Sprintf("Create new: %s-%s", datePrefix, q.Name())))
+	}
+	for _, tag := range q.Tags {
+		line.WriteString(tagStyle.Render(" #" + tag))
 	}
 
 	result := line.String()
@@ -1054,6 +1595,14 @@ func formatAge(t time.Time) string {
 	}
 }
 
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
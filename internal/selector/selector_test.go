@@ -0,0 +1,86 @@
+package selector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/rank"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("TRY_CONFIG", filepath.Join(os.TempDir(), "try-test-no-config.toml"))
+	os.Unsetenv("TRY_PROFILE")
+	os.Exit(m.Run())
+}
+
+// testRoot creates the named experiments in a fresh tries root and
+// returns it with its projects directory.
+func testRoot(t *testing.T, names ...string) (root, projects string) {
+	t.Helper()
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root = filepath.Join(tmpDir, "tries")
+	projects = filepath.Join(tmpDir, "projects")
+	for _, name := range names {
+		if err := os.MkdirAll(filepath.Join(root, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(projects, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRY_PATH", root)
+	t.Setenv("TRY_PROJECTS", projects)
+	return root, projects
+}
+
+// loadModel returns the selector over the entries of root.
+func loadModel(t *testing.T, root string) model {
+	t.Helper()
+	entries, err := entry.LoadEntries(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	km, err := loadKeyMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newModel(entries, "", rank.DefaultWeights, km, false)
+}
+
+// testModel returns the selector over the named experiments in a fresh
+// tries root, with the root and its projects directory.
+func testModel(t *testing.T, names ...string) (model, string, string) {
+	t.Helper()
+	root, projects := testRoot(t, names...)
+	return loadModel(t, root), root, projects
+}
+
+// press feeds msgs to m's Update in order, as the program would.
+func press(t *testing.T, m model, msgs ...tea.Msg) model {
+	t.Helper()
+	for _, msg := range msgs {
+		next, _ := m.Update(msg)
+		nm, ok := next.(model)
+		if !ok {
+			t.Fatalf("Update(%v) returned %T", msg, next)
+		}
+		m = nm
+	}
+	return m
+}
+
+func key(k tea.KeyType) tea.KeyMsg {
+	return tea.KeyMsg{Type: k}
+}
+
+func typed(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var space = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
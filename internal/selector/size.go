@@ -0,0 +1,69 @@
+package selector
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/xpzouying/try/internal/du"
+)
+
+// sizeWorkers bounds how many directory walks run at once.
+const sizeWorkers = 4
+
+// sizeMsg delivers one entry's disk usage and carries the channel the
+// remaining results arrive on.
+type sizeMsg struct {
+	result  du.Result
+	results <-chan du.Result
+}
+
+// loadSizes measures every entry in the background. The walks stop when
+// the selector exits, since m.ctx is cancelled then.
+func (m model) loadSizes() tea.Cmd {
+	// Graduated entries are symlinks; their projects live elsewhere
+	var paths []string
+	for _, e := range m.entries {
+		if !e.Graduated() {
+			paths = append(paths, e.Path)
+		}
+	}
+	return func() tea.Msg {
+		return nextSize(du.SizeAll(m.ctx, paths, sizeWorkers))()
+	}
+}
+
+// nextSize waits for the next result; it yields nothing once all are in.
+func nextSize(results <-chan du.Result) tea.Cmd {
+	return func() tea.Msg {
+		r, ok := <-results
+		if !ok {
+			return nil
+		}
+		return sizeMsg{result: r, results: results}
+	}
+}
+
+// sortBySize orders the filtered entries biggest first, unmeasured ones
+// last, keeping the highlighted entry under the cursor.
+func (m *model) sortBySize() {
+	var current string
+	if !m.isCreateSelected() && len(m.filtered) > 0 {
+		current = m.filtered[m.cursor].entry.Path
+	}
+
+	sort.SliceStable(m.filtered, func(i, j int) bool {
+		si, iok := m.sizes[m.filtered[i].entry.Path]
+		sj, jok := m.sizes[m.filtered[j].entry.Path]
+		if iok != jok {
+			return iok
+		}
+		return si > sj
+	})
+
+	for i, fe := range m.filtered {
+		if fe.entry.Path == current {
+			m.cursor = i
+			break
+		}
+	}
+}
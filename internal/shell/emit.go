@@ -0,0 +1,170 @@
+package shell
+
+import (
+	"os"
+	"strings"
+)
+
+// Emitter builds a command line for the shell wrapper to eval.
+// Every argument is quoted for the target shell, so hostile directory
+// names like `x$(rm -rf ~)` are passed through as literal words.
+type Emitter struct {
+	shell string
+	cmds  []string
+}
+
+// NewEmitter returns an Emitter for the given shell.
+// Unknown shells fall back to POSIX quoting.
+func NewEmitter(shellName string) *Emitter {
+	return &Emitter{shell: normalize(shellName)}
+}
+
+// Target returns the shell the wrapper is running in.
+// The wrapper exports TRY_SHELL; otherwise fall back to $SHELL.
+func Target() string {
+	if name := os.Getenv("TRY_SHELL"); name != "" {
+		return name
+	}
+	return Detect()
+}
+
+// Shell returns the normalized shell name this Emitter targets.
+func (e *Emitter) Shell() string {
+	return e.shell
+}
+
+// Quote quotes s as a single word for the emitter's shell.
+func (e *Emitter) Quote(s string) string {
+	return Quote(e.shell, s)
+}
+
+// Cd emits `cd <path>`.
+func (e *Emitter) Cd(path string) *Emitter {
+	return e.add("cd", path)
+}
+
+// Mkdir emits `mkdir -p <path>`.
+func (e *Emitter) Mkdir(path string) *Emitter {
+	return e.add("mkdir", "-p", path)
+}
+
+// Echo emits `echo <msg>`.
+func (e *Emitter) Echo(msg string) *Emitter {
+	return e.add("echo", msg)
+}
+
+// GitClone emits `git clone [args...] <url> <dest>`.
+func (e *Emitter) GitClone(url, dest string, args ...string) *Emitter {
+	words := append([]string{"git", "clone"}, args...)
+	return e.add(append(words, "--", url, dest)...)
+}
+
+// GitWorktreeAdd emits a detached `git worktree add` run from repo.
+// Failure is tolerated so the wrapper still cds into the directory; the
+// fallback is grouped so it can't swallow failures of earlier commands.
+func (e *Emitter) GitWorktreeAdd(repo, path string) *Emitter {
+	e.cmds = append(e.cmds, e.group(e.join("git", "-C", repo, "worktree", "add", "--detach", path)+" 2>/dev/null || true"))
+	return e
+}
+
+// Raw appends a command verbatim, e.g. a user-configured hook.
+func (e *Emitter) Raw(cmd string) *Emitter {
+	e.cmds = append(e.cmds, cmd)
+	return e
+}
+
+// Len returns the number of emitted commands.
+func (e *Emitter) Len() int {
+	return len(e.cmds)
+}
+
+// String joins the commands with && and terminates the line.
+func (e *Emitter) String() string {
+	if len(e.cmds) == 0 {
+		return ""
+	}
+	return strings.Join(e.cmds, " && ") + "\n"
+}
+
+func (e *Emitter) add(words ...string) *Emitter {
+	e.cmds = append(e.cmds, e.join(words...))
+	return e
+}
+
+// group wraps cmd so a || inside it binds tighter than the && chain.
+func (e *Emitter) group(cmd string) string {
+	if e.shell == "fish" {
+		return "begin; " + cmd + "; end"
+	}
+	return "{ " + cmd + "; }"
+}
+
+func (e *Emitter) join(words ...string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		if isBareWord(w) {
+			quoted[i] = w
+		} else {
+			quoted[i] = e.Quote(w)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Quote quotes s as a single word for the given shell.
+func Quote(shellName, s string) string {
+	if normalize(shellName) == "fish" {
+		return quoteFish(s)
+	}
+	return quotePOSIX(s)
+}
+
+// quotePOSIX wraps s in single quotes. Nothing is special inside single
+// quotes in sh/bash/zsh, so an embedded quote is closed, escaped and reopened.
+func quotePOSIX(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish wraps s in single quotes. Fish honours \\ and \' inside single
+// quotes, and the wrapper's `eval $output` joins lines with spaces, so
+// newlines are emitted as an unquoted \n escape.
+func quoteFish(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\n':
+			b.WriteString(`'\n'`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// isBareWord reports whether w is a fixed command word or flag that needs no quoting.
+func isBareWord(w string) bool {
+	if w == "" {
+		return false
+	}
+	for _, r := range w {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(shellName string) string {
+	switch name := strings.ToLower(shellName); name {
+	case "bash", "zsh", "fish":
+		return name
+	default:
+		return "sh"
+	}
+}
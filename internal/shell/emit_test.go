@@ -0,0 +1,207 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// hostileNames are directory names that break naive quoting.
+var hostileNames = []string{
+	"plain",
+	"with space",
+	"x$(rm -rf ~)",
+	"`touch pwned`",
+	"it's",
+	`back\slash`,
+	`"double"`,
+	"semi;colon && echo hi",
+	"glob*?[a]",
+	"$HOME",
+	"{a,b}",
+	"~tilde",
+	"-n",
+	"new\nline",
+	"tab\there",
+	"trailing\\",
+	"'",
+	"''",
+	"\\'",
+	"emoji-🚀",
+	"(paren) | pipe > redir < in",
+	"#comment",
+	"%percent",
+}
+
+func TestQuote_POSIX(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"plain", "'plain'"},
+		{"it's", `'it'\''s'`},
+		{"x$(rm -rf ~)", "'x$(rm -rf ~)'"},
+		{"", "''"},
+	}
+	for _, tc := range tests {
+		for _, sh := range []string{"bash", "zsh", "sh"} {
+			if got := Quote(sh, tc.in); got != tc.expected {
+				t.Errorf("Quote(%s, %q) = %s, expected %s", sh, tc.in, got, tc.expected)
+			}
+		}
+	}
+}
+
+func TestQuote_Fish(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"plain", "'plain'"},
+		{"it's", `'it\'s'`},
+		{`back\slash`, `'back\\slash'`},
+		{"a\nb", `'a'\n'b'`},
+		{"$HOME", "'$HOME'"},
+	}
+	for _, tc := range tests {
+		if got := Quote("fish", tc.in); got != tc.expected {
+			t.Errorf("Quote(fish, %q) = %s, expected %s", tc.in, got, tc.expected)
+		}
+	}
+}
+
+func TestQuote_UnknownShellFallsBackToPOSIX(t *testing.T) {
+	if got := Quote("powershell", "it's"); got != `'it'\''s'` {
+		t.Errorf("expected POSIX quoting, got %s", got)
+	}
+}
+
+// TestQuote_RoundTrip feeds every hostile name through each installed shell
+// and checks the shell sees exactly the original bytes.
+func TestQuote_RoundTrip(t *testing.T) {
+	for _, sh := range []string{"bash", "zsh", "fish", "sh"} {
+		bin, err := exec.LookPath(sh)
+		if err != nil {
+			continue
+		}
+		t.Run(sh, func(t *testing.T) {
+			for _, name := range hostileNames {
+				assertRoundTrip(t, bin, sh, name)
+			}
+		})
+	}
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, name := range hostileNames {
+		f.Add(name)
+	}
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		f.Skip("bash not installed")
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if strings.ContainsRune(s, 0) {
+			t.Skip("shell arguments cannot contain NUL")
+		}
+		assertRoundTrip(t, bash, "bash", s)
+	})
+}
+
+func assertRoundTrip(t *testing.T, bin, sh, s string) {
+	t.Helper()
+	script := "printf '%s' " + Quote(sh, s)
+	out, err := exec.Command(bin, "-c", script).Output()
+	if err != nil {
+		t.Errorf("%s: %q failed: %v", sh, s, err)
+		return
+	}
+	if string(out) != s {
+		t.Errorf("%s: round trip of %q produced %q", sh, s, out)
+	}
+}
+
+func TestEmitter_String(t *testing.T) {
+	e := NewEmitter("bash")
+	e.Mkdir("/tmp/a b").Cd("/tmp/a b")
+	expected := "mkdir -p '/tmp/a b' && cd '/tmp/a b'\n"
+	if got := e.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if got := NewEmitter("bash").String(); got != "" {
+		t.Errorf("empty emitter should produce no output, got %q", got)
+	}
+}
+
+func TestEmitter_Commands(t *testing.T) {
+	tests := []struct {
+		name     string
+		emit     func(e *Emitter)
+		expected string
+	}{
+		{"clone", func(e *Emitter) { e.GitClone("https://x/y", "/a", "--depth", "1") }, "git clone --depth 1 -- 'https://x/y' '/a'"},
+		{"worktree add", func(e *Emitter) { e.GitWorktreeAdd("/r", "/a") }, "{ git -C '/r' worktree add --detach '/a' 2>/dev/null || true; }"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEmitter("bash")
+			tc.emit(e)
+			if got := strings.TrimSuffix(e.String(), "\n"); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestEmitter_EvalHostileName evals emitted mkdir+cd the same way the bash
+// wrapper does and checks that exactly the hostile directory was created.
+func TestEmitter_EvalHostileName(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	for _, name := range hostileNames {
+		tmpDir := t.TempDir()
+		dir := filepath.Join(tmpDir, name)
+		e := NewEmitter("bash")
+		e.Mkdir(dir).Cd(dir)
+
+		cmd := exec.Command(bash, "-c", `eval "$1" && printf '%s' "$PWD"`, "bash", e.String())
+		cmd.Dir = tmpDir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Errorf("eval for %q failed: %v", name, err)
+			continue
+		}
+		if string(out) != dir {
+			t.Errorf("expected cwd %q, got %q", dir, out)
+		}
+		entries, _ := os.ReadDir(tmpDir)
+		if len(entries) != 1 || entries[0].Name() != name {
+			t.Errorf("expected only %q in temp dir, got %v", name, entries)
+		}
+	}
+}
+
+func TestEmitter_GroupsFallbacks(t *testing.T) {
+	fish := NewEmitter("fish")
+	fish.GitWorktreeAdd("/r", "/a")
+	if got, expected := fish.String(), "begin; git -C '/r' worktree add --detach '/a' 2>/dev/null || true; end\n"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	// A failed step must stop the chain, fallback or not
+	e := NewEmitter("bash")
+	e.Raw("false").GitWorktreeAdd("/nonexistent", "/nonexistent/wt").Echo("ran")
+	out, err := exec.Command(bash, "-c", `eval "$1"`, "bash", e.String()).Output()
+	if err == nil || strings.Contains(string(out), "ran") {
+		t.Errorf("expected the chain to stop after false, got %q, %v", out, err)
+	}
+}
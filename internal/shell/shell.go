@@ -7,6 +7,10 @@ import (
 	"strings"
 )
 
+// Passthrough lists glob patterns for subcommands whose stdout is data rather
+// than a script, so the wrapper runs them directly instead of through `exec`.
+var Passthrough = []string{"init", "list", "du", "clean", "prune", "trash", "archive", "restore", "demote", "config", "meta", "tag", "pin", "unpin", "visit", "--filter*"}
+
 // Detect returns the current shell name from SHELL environment variable.
 func Detect() string {
 	shell := os.Getenv("SHELL")
@@ -17,6 +21,7 @@ func Detect() string {
 }
 
 // Wrapper returns the shell wrapper function for the given shell.
+// The wrapper exports TRY_SHELL so exec output is quoted for that shell.
 func Wrapper(shellName string) (string, error) {
 	// Get the path to the try binary
 	executable, err := os.Executable()
@@ -26,11 +31,11 @@ func Wrapper(shellName string) (string, error) {
 
 	switch strings.ToLower(shellName) {
 	case "bash", "sh":
-		return bashWrapper(executable), nil
+		return bashWrapper(quotePOSIX(executable)), nil
 	case "zsh":
-		return zshWrapper(executable), nil
+		return zshWrapper(quotePOSIX(executable)), nil
 	case "fish":
-		return fishWrapper(executable), nil
+		return fishWrapper(quoteFish(executable)), nil
 	default:
 		return "", fmt.Errorf("unsupported shell: %s (supported: bash, zsh, fish)", shellName)
 	}
@@ -41,20 +46,22 @@ func bashWrapper(tryPath string) string {
 # Add this to your ~/.bashrc
 
 try() {
-  # 'init' must bypass exec (it generates this wrapper)
-  if [[ "$1" == "init" ]]; then
-    %[1]q "$@"
-    return $?
-  fi
+  # Commands that print to stdout must bypass exec (their output is not a script)
+  case "$1" in
+    %[3]s)
+      %[1]s "$@"
+      return $?
+      ;;
+  esac
   local output
-  output=$(%[1]q exec "$@")
+  output=$(TRY_SHELL=%[2]s %[1]s exec "$@")
   local exit_code=$?
   if [[ $exit_code -eq 0 && -n "$output" ]]; then
     eval "$output"
   fi
   return $exit_code
 }
-`, tryPath)
+`, tryPath, "bash", strings.Join(Passthrough, "|"))
 }
 
 func zshWrapper(tryPath string) string {
@@ -62,20 +69,22 @@ func zshWrapper(tryPath string) string {
 # Add this to your ~/.zshrc
 
 try() {
-  # 'init' must bypass exec (it generates this wrapper)
-  if [[ "$1" == "init" ]]; then
-    %[1]q "$@"
-    return $?
-  fi
+  # Commands that print to stdout must bypass exec (their output is not a script)
+  case "$1" in
+    %[3]s)
+      %[1]s "$@"
+      return $?
+      ;;
+  esac
   local output
-  output=$(%[1]q exec "$@")
+  output=$(TRY_SHELL=%[2]s %[1]s exec "$@")
   local exit_code=$?
   if [[ $exit_code -eq 0 && -n "$output" ]]; then
     eval "$output"
   fi
   return $exit_code
 }
-`, tryPath)
+`, tryPath, "zsh", strings.Join(Passthrough, "|"))
 }
 
 func fishWrapper(tryPath string) string {
@@ -83,17 +92,69 @@ func fishWrapper(tryPath string) string {
 # Add this to your ~/.config/fish/config.fish
 
 function try
-  # 'init' must bypass exec (it generates this wrapper)
-  if test "$argv[1]" = "init"
-    %[1]s $argv
-    return $status
+  # Commands that print to stdout must bypass exec (their output is not a script)
+  for pattern in %[2]s
+    if string match -q -- $pattern "$argv[1]"
+      %[1]s $argv
+      return $status
+    end
   end
-  set -l output (%[1]s exec $argv)
+  set -l output (env TRY_SHELL=fish %[1]s exec $argv)
   set -l exit_code $status
   if test $exit_code -eq 0 -a -n "$output"
     eval $output
   end
   return $exit_code
 end
-`, tryPath)
+`, tryPath, fishPatterns())
+}
+
+// fishPatterns quotes Passthrough so fish matches rather than expands the globs.
+func fishPatterns() string {
+	quoted := make([]string, len(Passthrough))
+	for i, p := range Passthrough {
+		quoted[i] = quoteFish(p)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Hook returns an optional snippet that records a visit whenever the
+// current directory changes, so `cd` outside try still feeds frecency.
+func Hook(shellName string) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "try"
+	}
+
+	switch strings.ToLower(shellName) {
+	case "bash", "sh":
+		return fmt.Sprintf(`
+# Record visits to experiments when the directory changes
+__try_visit() {
+  if [[ "$PWD" != "$__try_last_pwd" ]]; then
+    __try_last_pwd="$PWD"
+    %[1]s visit "$PWD" 2>/dev/null
+  fi
+}
+PROMPT_COMMAND="__try_visit${PROMPT_COMMAND:+; $PROMPT_COMMAND}"
+`, quotePOSIX(executable)), nil
+	case "zsh":
+		return fmt.Sprintf(`
+# Record visits to experiments when the directory changes
+__try_visit() {
+  %[1]s visit "$PWD" 2>/dev/null
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd __try_visit
+`, quotePOSIX(executable)), nil
+	case "fish":
+		return fmt.Sprintf(`
+# Record visits to experiments when the directory changes
+function __try_visit --on-variable PWD
+  %[1]s visit "$PWD" 2>/dev/null
+end
+`, quoteFish(executable)), nil
+	default:
+		return "", fmt.Errorf("unsupported shell: %s (supported: bash, zsh, fish)", shellName)
+	}
 }
@@ -50,9 +50,9 @@ func TestWrapper(t *testing.T) {
 		shell    string
 		contains []string
 	}{
-		{"bash", []string{"try()", "eval \"$output\"", "exec", "~/.bashrc"}},
-		{"zsh", []string{"try()", "~/.zshrc"}},
-		{"fish", []string{"function try", "eval $output", "config.fish"}},
+		{"bash", []string{"try()", "eval \"$output\"", "exec", "~/.bashrc", "TRY_SHELL=bash"}},
+		{"zsh", []string{"try()", "~/.zshrc", "TRY_SHELL=zsh"}},
+		{"fish", []string{"function try", "eval $output", "config.fish", "TRY_SHELL=fish"}},
 		{"sh", []string{"try()"}},
 	}
 
@@ -105,3 +105,30 @@ func TestWrapper_InitBypass(t *testing.T) {
 		}
 	}
 }
+
+func TestHook(t *testing.T) {
+	tests := []struct {
+		shell    string
+		contains string
+	}{
+		{"bash", "PROMPT_COMMAND"},
+		{"zsh", "add-zsh-hook chpwd"},
+		{"fish", "--on-variable PWD"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			hook, err := Hook(tc.shell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(hook, tc.contains) || !strings.Contains(hook, "visit") {
+				t.Errorf("hook should contain %q and call visit:\n%s", tc.contains, hook)
+			}
+		})
+	}
+
+	if _, err := Hook("powershell"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
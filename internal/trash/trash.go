@@ -0,0 +1,176 @@
+package trash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/xpzouying/try/internal/action"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+// DirName is the trash directory under a tries root. LoadEntries skips
+// dot-directories, so it never shows up as an experiment.
+const DirName = ".trash"
+
+// infoFile records where a trashed item came from, next to its files.
+const infoFile = "trash.json"
+
+// idLayout prefixes item IDs so they sort by deletion time.
+const idLayout = "20060102-150405"
+
+// Item is a trashed experiment, stored as .trash/<id>/<name> with its
+// record in .trash/<id>/trash.json.
+type Item struct {
+	ID        string     `json:"-"`
+	Dir       string     `json:"-"` // .trash/<id>
+	Name      string     `json:"name"`
+	Origin    string     `json:"origin"` // Path it was deleted from
+	DeletedAt time.Time  `json:"deleted_at"`
+	Worktree  bool       `json:"worktree,omitempty"`
+	Meta      *meta.Meta `json:"meta,omitempty"`
+}
+
+// Dir returns the trash directory of a tries root.
+func Dir(root string) string {
+	return filepath.Join(root, DirName)
+}
+
+// Path returns where the item's files are kept.
+func (it *Item) Path() string {
+	return filepath.Join(it.Dir, it.Name)
+}
+
+// Put moves the experiment at path into its root's trash with its
+// metadata. Worktrees are moved with git, so their source repository
+// keeps tracking them and restoring them needs no repair. A graduated
+// symlink is moved as a plain link; its project stays where it is.
+func Put(path string, m *meta.Meta, now time.Time) (*Item, error) {
+	root := filepath.Dir(path)
+	name := filepath.Base(path)
+
+	id := now.Format(idLayout) + "-" + name
+	dir := filepath.Join(Dir(root), id)
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(dir); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%s-%d", now.Format(idLayout), name, i)
+		dir = filepath.Join(Dir(root), id)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	it := &Item{
+		ID:        id,
+		Dir:       dir,
+		Name:      name,
+		Origin:    path,
+		DeletedAt: now,
+		Worktree:  action.IsWorktree(path),
+		Meta:      m,
+	}
+	if err := writeInfo(it); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := action.Rename(path, it.Path()); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return it, nil
+}
+
+func writeInfo(it *Item) error {
+	data, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(it.Dir, infoFile), append(data, '\n'), 0644)
+}
+
+// List returns the items in a root's trash, most recently deleted first.
+// Entries without a readable record are skipped.
+func List(root string) ([]*Item, error) {
+	dirs, err := os.ReadDir(Dir(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var items []*Item
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(Dir(root), d.Name())
+		data, err := os.ReadFile(filepath.Join(dir, infoFile))
+		if err != nil {
+			continue
+		}
+		it := &Item{}
+		if err := json.Unmarshal(data, it); err != nil {
+			continue
+		}
+		// The name is joined to the item's directory; never let it climb out
+		if it.Name == "" || it.Name != filepath.Base(it.Name) || it.Name == ".." {
+			continue
+		}
+		it.ID = d.Name()
+		it.Dir = dir
+		items = append(items, it)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Restore moves the item back to where it was deleted from and drops
+// it from the trash. The caller restores it.Meta.
+func Restore(it *Item) error {
+	if _, err := os.Lstat(it.Origin); err == nil {
+		return fmt.Errorf("restore %s: %s already exists", it.Name, it.Origin)
+	}
+	if err := action.Rename(it.Path(), it.Origin); err != nil {
+		return fmt.Errorf("restore %s: %w", it.Name, err)
+	}
+	return os.RemoveAll(it.Dir)
+}
+
+// Remove deletes the item for good. Worktrees are removed with git so
+// the source repository forgets them.
+func Remove(it *Item) error {
+	if _, err := os.Lstat(it.Path()); err == nil {
+		if err := action.Delete(it.Path()); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(it.Dir)
+}
+
+// Expire removes the items of a root's trash deleted before cutoff and
+// returns them.
+func Expire(root string, cutoff time.Time) ([]*Item, error) {
+	items, err := List(root)
+	if err != nil {
+		return nil, err
+	}
+	var expired []*Item
+	for _, it := range items {
+		if !it.DeletedAt.Before(cutoff) {
+			continue
+		}
+		if err := Remove(it); err != nil {
+			return expired, fmt.Errorf("expire %s: %w", it.ID, err)
+		}
+		expired = append(expired, it)
+	}
+	return expired, nil
+}
@@ -0,0 +1,225 @@
+package trash
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xpzouying/try/internal/meta"
+)
+
+func TestPutRestore(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "2024-01-15-redis")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "notes.md"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	it, err := Put(path, &meta.Meta{Description: "cache test"}, now)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if it.ID != "20240201-120000-2024-01-15-redis" {
+		t.Errorf("ID = %q", it.ID)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected original moved away")
+	}
+
+	// Same name deleted again in the same second gets its own slot
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Put(path, nil, now); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("List() = %d items, expected 2", len(items))
+	}
+
+	var first *Item
+	for _, item := range items {
+		if item.ID == it.ID {
+			first = item
+		}
+	}
+	if first == nil || first.Origin != path || first.Meta == nil || first.Meta.Description != "cache test" || !first.DeletedAt.Equal(now) {
+		t.Fatalf("unexpected record: %+v", first)
+	}
+
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(first); err == nil {
+		t.Error("expected error while the origin is taken")
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(first); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(path, "notes.md")); err != nil || string(data) != "hello" {
+		t.Errorf("notes.md not restored: %q, %v", data, err)
+	}
+	if items, _ := List(root); len(items) != 1 {
+		t.Errorf("expected one item left, got %d", len(items))
+	}
+}
+
+func TestExpire(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"old", "new"} {
+		path := filepath.Join(root, name)
+		if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Put(path, nil, now.Add(-time.Duration(40-30*i)*24*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expired, err := Expire(root, now.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].Name != "old" {
+		t.Errorf("Expire() = %+v", expired)
+	}
+	items, _ := List(root)
+	if len(items) != 1 || items[0].Name != "new" {
+		t.Errorf("expected only new left, got %+v", items)
+	}
+}
+
+func TestWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(tmpDir, "repo")
+	root := filepath.Join(tmpDir, "tries")
+	wt := filepath.Join(root, "2024-01-15-feature")
+	for _, dir := range []string{repo, root} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	git("init", "-q")
+	git("-c", "user.name=try", "-c", "user.email=try@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	git("worktree", "add", "-q", "--detach", wt)
+
+	it, err := Put(wt, nil, time.Now())
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if !it.Worktree || !strings.Contains(git("worktree", "list"), it.Path()) {
+		t.Error("git should track the worktree in the trash")
+	}
+
+	if err := Restore(it); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if !strings.Contains(git("worktree", "list"), wt) {
+		t.Error("git should track the restored worktree")
+	}
+
+	it, err = Put(wt, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(it); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if strings.Contains(git("worktree", "list"), "feature") {
+		t.Error("git should forget the removed worktree")
+	}
+}
+
+func TestGraduatedWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(tmpDir, "repo")
+	root := filepath.Join(tmpDir, "tries")
+	project := filepath.Join(tmpDir, "projects", "feature")
+	link := filepath.Join(root, "2024-01-15-feature")
+	for _, dir := range []string{repo, root, filepath.Dir(project)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	git("init", "-q")
+	git("-c", "user.name=try", "-c", "user.email=try@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	git("worktree", "add", "-q", "--detach", project)
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(project, link); err != nil {
+		t.Fatal(err)
+	}
+	before := git("worktree", "list")
+
+	it, err := Put(link, nil, time.Now())
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if it.Worktree {
+		t.Error("a symlink to a worktree should not be trashed as a worktree")
+	}
+	if info, err := os.Lstat(it.Path()); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("the trash should hold the link itself, got %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(project, "main.go")); err != nil {
+		t.Errorf("project should be left in place: %v", err)
+	}
+	if got := git("worktree", "list"); got != before {
+		t.Errorf("git worktree list changed:\n%s\nwant:\n%s", got, before)
+	}
+
+	if err := Remove(it); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, "main.go")); err != nil {
+		t.Errorf("removing the link should leave the project: %v", err)
+	}
+	if got := git("worktree", "list"); got != before {
+		t.Errorf("git worktree list changed:\n%s\nwant:\n%s", got, before)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xpzouying/try/internal/entry"
+)
+
+// listItem is the machine-readable form of an entry for `try list`.
+type listItem struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	ModTime    time.Time `json:"mod_time"`
+	BaseName   string    `json:"base_name"`
+	IsWorktree bool      `json:"is_worktree"`
+	SourceRepo string    `json:"source_repo,omitempty"`
+	Profile    string    `json:"profile,omitempty"`
+	Target     string    `json:"target,omitempty"` // For graduated entries
+	Broken     bool      `json:"broken,omitempty"`
+
+	// From recorded metadata
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// runList prints experiments without opening the selector.
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	format := fs.String("format", "plain", "output format: plain, tsv or json")
+	sortBy := fs.String("sort", "mtime", "sort key: mtime or name")
+	reverse := fs.Bool("reverse", false, "reverse the sort order")
+	match := fs.String("match", "", "only entries whose name contains this substring")
+	worktrees := fs.Bool("worktrees", false, "only git worktrees")
+	source := fs.String("source", "", "only worktrees of this source repository")
+	tag := fs.String("tag", "", "only entries with this tag")
+	limit := fs.Int("limit", 0, "maximum number of entries (0 = no limit)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, err := entry.LoadAll()
+	if err != nil {
+		return fmt.Errorf("load entries: %w", err)
+	}
+
+	var items []listItem
+	for _, e := range entries {
+		if *match != "" && !strings.Contains(strings.ToLower(e.Name), strings.ToLower(*match)) {
+			continue
+		}
+		if *worktrees && !e.IsWorktree {
+			continue
+		}
+		if *source != "" && e.SourceRepo != *source {
+			continue
+		}
+		if *tag != "" && !e.HasTags([]string{*tag}) {
+			continue
+		}
+		items = append(items, newListItem(e))
+	}
+
+	if err := sortListItems(items, *sortBy, *reverse); err != nil {
+		return err
+	}
+	if *limit > 0 && len(items) > *limit {
+		items = items[:*limit]
+	}
+
+	return writeListItems(os.Stdout, items, *format)
+}
+
+func newListItem(e *entry.Entry) listItem {
+	it := listItem{
+		Name:       e.Name,
+		Path:       e.Path,
+		ModTime:    e.ModTime,
+		BaseName:   e.BaseName,
+		IsWorktree: e.IsWorktree,
+		SourceRepo: e.SourceRepo,
+		Profile:    e.Profile,
+		Target:     e.Target,
+		Broken:     e.Broken,
+	}
+	if m := e.Meta; m != nil {
+		it.Description = m.Description
+		it.Tags = m.Tags
+		if !m.CreatedAt.IsZero() {
+			it.CreatedAt = &m.CreatedAt
+		}
+	}
+	return it
+}
+
+func sortListItems(items []listItem, key string, reverse bool) error {
+	var less func(a, b listItem) bool
+	switch key {
+	case "mtime":
+		// Newest first, like the selector
+		less = func(a, b listItem) bool { return a.ModTime.After(b.ModTime) }
+	case "name":
+		less = func(a, b listItem) bool { return a.Name < b.Name }
+	default:
+		return fmt.Errorf("unknown sort key: %s (supported: mtime, name)", key)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if reverse {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+	return nil
+}
+
+func writeListItems(w io.Writer, items []listItem, format string) error {
+	switch format {
+	case "json":
+		if items == nil {
+			items = []listItem{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case "tsv":
+		fmt.Fprintln(w, "name\tpath\tmod_time\tbase_name\tis_worktree\tsource_repo\ttags\tdescription")
+		for _, it := range items {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%s\t%s\n",
+				tsvField(it.Name), tsvField(it.Path), it.ModTime.Format(time.RFC3339), tsvField(it.BaseName), it.IsWorktree,
+				tsvField(it.SourceRepo), tsvField(strings.Join(it.Tags, ",")), tsvField(it.Description))
+		}
+		return nil
+	case "plain":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, it := range items {
+			source := ""
+			if it.IsWorktree && it.SourceRepo != "" {
+				source = "← " + it.SourceRepo
+			}
+			if it.Target != "" {
+				source = "→ " + it.Target
+				if it.Broken {
+					source += " (broken)"
+				}
+			}
+			name := it.Name
+			if it.Profile != "" {
+				name = "[" + it.Profile + "] " + name
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, it.ModTime.Format("2006-01-02 15:04"), source, it.Description)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format: %s (supported: plain, tsv, json)", format)
+	}
+}
+
+// tsvField keeps a string on one TSV cell; directory names can contain
+// tabs and newlines too.
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteListItems_JSON(t *testing.T) {
+	items := []listItem{{
+		Name:       "2024-01-15-redis",
+		Path:       "/tmp/tries/2024-01-15-redis",
+		ModTime:    time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		BaseName:   "redis",
+		IsWorktree: true,
+		SourceRepo: "redis",
+	}}
+
+	var buf bytes.Buffer
+	if err := writeListItems(&buf, items, "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []listItem
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(decoded) != 1 || decoded[0].Name != items[0].Name || !decoded[0].ModTime.Equal(items[0].ModTime) || decoded[0].SourceRepo != items[0].SourceRepo {
+		t.Errorf("round trip mismatch: %+v", decoded)
+	}
+	if !strings.Contains(buf.String(), `"is_worktree": true`) {
+		t.Errorf("expected snake_case keys, got %s", buf.String())
+	}
+}
+
+func TestWriteListItems_EmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeListItems(&buf, nil, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected empty array, got %q", buf.String())
+	}
+}
+
+func TestWriteListItems_PlainGraduated(t *testing.T) {
+	items := []listItem{
+		{Name: "2024-01-15-redis", Target: "/projects/redis"},
+		{Name: "2024-01-14-gone", Target: "/projects/gone", Broken: true},
+	}
+
+	var buf bytes.Buffer
+	if err := writeListItems(&buf, items, "plain"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "→ /projects/redis") || !strings.Contains(out, "→ /projects/gone (broken)") {
+		t.Errorf("expected targets with the broken one flagged, got:\n%s", out)
+	}
+}
+
+func TestWriteListItems_TSV(t *testing.T) {
+	items := []listItem{{
+		Name:     "2024-01-15-redis",
+		Path:     "/tmp/tries/2024-01-15-redis",
+		ModTime:  time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		BaseName: "redis",
+	}}
+
+	var buf bytes.Buffer
+	if err := writeListItems(&buf, items, "tsv"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one row, got %d lines", len(lines))
+	}
+	fields := strings.Split(lines[1], "\t")
+	if len(fields) != 8 {
+		t.Fatalf("expected 8 fields, got %d: %q", len(fields), lines[1])
+	}
+	if fields[2] != "2024-01-15T10:00:00Z" || fields[4] != "false" {
+		t.Errorf("unexpected row: %q", lines[1])
+	}
+}
+
+func TestWriteListItems_TSVEscapesFields(t *testing.T) {
+	items := []listItem{{
+		Name:        "2024-01-15-a\tb",
+		Path:        "/tmp/tries/2024-01-15-a\tb",
+		BaseName:    "a\nb",
+		SourceRepo:  "/src/re\tpo",
+		Tags:        []string{"x\ty", "z"},
+		Description: "line one\r\nline two",
+	}}
+
+	var buf bytes.Buffer
+	if err := writeListItems(&buf, items, "tsv"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one row, got %d lines: %q", len(lines), buf.String())
+	}
+	fields := strings.Split(lines[1], "\t")
+	if len(fields) != 8 {
+		t.Fatalf("expected 8 fields, got %d: %q", len(fields), lines[1])
+	}
+	if fields[0] != "2024-01-15-a b" || fields[3] != "a b" || fields[5] != "/src/re po" || fields[6] != "x y,z" {
+		t.Errorf("unexpected row: %q", lines[1])
+	}
+}
+
+func TestWriteListItems_UnknownFormat(t *testing.T) {
+	if err := writeListItems(io.Discard, nil, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestSortListItems(t *testing.T) {
+	now := time.Now()
+	items := []listItem{
+		{Name: "b", ModTime: now.Add(-time.Hour)},
+		{Name: "a", ModTime: now.Add(-2 * time.Hour)},
+		{Name: "c", ModTime: now},
+	}
+
+	tests := []struct {
+		key      string
+		reverse  bool
+		expected string
+	}{
+		{"mtime", false, "cba"},
+		{"mtime", true, "abc"},
+		{"name", false, "abc"},
+		{"name", true, "cba"},
+	}
+
+	for _, tc := range tests {
+		sorted := append([]listItem(nil), items...)
+		if err := sortListItems(sorted, tc.key, tc.reverse); err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		for _, it := range sorted {
+			got += it.Name
+		}
+		if got != tc.expected {
+			t.Errorf("sort %s reverse=%v: expected %s, got %s", tc.key, tc.reverse, tc.expected, got)
+		}
+	}
+
+	if err := sortListItems(items, "size", false); err == nil {
+		t.Error("expected error for unknown sort key")
+	}
+}
+
+// Integration test: list with filters
+func TestRun_List(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+	for _, name := range []string{"2024-01-15-redis", "2024-01-16-postgres", "notes"} {
+		if err := os.Mkdir(filepath.Join(tmpDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run([]string{"list", "--format", "plain", "--sort", "name", "--match", "2024"})
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("run(list) returned error: %v", err)
+	}
+
+	out, _ := io.ReadAll(r)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 matching entries, got %d: %q", len(lines), out)
+	}
+	if !strings.HasPrefix(lines[0], "2024-01-15-redis") || !strings.HasPrefix(lines[1], "2024-01-16-postgres") {
+		t.Errorf("unexpected order: %q", lines)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -9,16 +10,27 @@ import (
 	"strings"
 	"time"
 
+	"github.com/xpzouying/try/internal/action"
+	"github.com/xpzouying/try/internal/config"
 	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/frecency"
+	"github.com/xpzouying/try/internal/meta"
+	"github.com/xpzouying/try/internal/rank"
 	"github.com/xpzouying/try/internal/selector"
 	"github.com/xpzouying/try/internal/shell"
+	"github.com/xpzouying/try/internal/trash"
 )
 
 var version = "dev"
 
+// errNoMatch makes try exit 1 without a message, like fzf --filter.
+var errNoMatch = errors.New("no match")
+
 func main() {
 	if err := run(os.Args[1:]); err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
+		if !errors.Is(err, errNoMatch) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
 		os.Exit(1)
 	}
 }
@@ -35,10 +47,42 @@ func run(args []string) error {
 		return nil
 	}
 
+	// Config overrides: try -c key=value / --profile name ... (also after exec, as the wrapper passes them)
+	args, overrides, err := parseOverrides(args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && args[0] == "exec" {
+		rest, more, err := parseOverrides(args[1:])
+		if err != nil {
+			return err
+		}
+		if len(more) > 0 && len(rest) > 0 && isPassthrough(rest[0]) {
+			return fmt.Errorf("%s prints data rather than a script: run `command try <flags> %s ...` to bypass the shell wrapper", rest[0], rest[0])
+		}
+		args = append([]string{"exec"}, rest...)
+		overrides = append(overrides, more...)
+	}
+	if err := config.Init(overrides); err != nil {
+		return err
+	}
+
 	if len(args) == 0 {
 		return runExec("")
 	}
 
+	// fzf-compatible headless ranking: --filter <query> or --filter=<query>
+	if args[0] == "--filter" || strings.HasPrefix(args[0], "--filter=") {
+		query, ok := strings.CutPrefix(args[0], "--filter=")
+		if !ok {
+			if len(args) < 2 {
+				return fmt.Errorf("--filter requires a query argument")
+			}
+			query = args[1]
+		}
+		return runFilter(query)
+	}
+
 	// Handle . and ./path for worktree creation
 	if strings.HasPrefix(args[0], ".") {
 		return runWorktree(args)
@@ -57,6 +101,32 @@ func run(args []string) error {
 			return runWorktree(args[1:]) // Pass "." and any additional args
 		}
 		return runExec(query)
+	case "list":
+		return runList(args[1:])
+	case "du":
+		return runDu(args[1:])
+	case "clean":
+		return runClean(args[1:])
+	case "prune":
+		return runPrune(args[1:])
+	case "trash":
+		return runTrash(args[1:])
+	case "archive":
+		return runArchive(args[1:])
+	case "demote":
+		return runDemote(args[1:])
+	case "restore":
+		return runRestore(args[1:])
+	case "config":
+		return runConfig(args[1:])
+	case "meta":
+		return runMeta(args[1:])
+	case "tag":
+		return runTag(args[1:])
+	case "visit":
+		return runVisit(args[1:])
+	case "pin", "unpin":
+		return runPin(args[1:], args[0] == "pin")
 	case "clone":
 		if len(args) < 2 {
 			return fmt.Errorf("clone requires a URL argument")
@@ -85,18 +155,29 @@ func containsAny(args []string, targets ...string) bool {
 
 func runInit(args []string) error {
 	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	hook := fs.Bool("hook", false, "also record visits on every directory change")
 	_ = fs.Parse(args)
 
 	shellName := fs.Arg(0)
 	if shellName == "" {
 		// Auto-detect from SHELL env
 		shellName = shell.Detect()
+	} else {
+		// Allow flags after the shell name too: try init zsh --hook
+		_ = fs.Parse(fs.Args()[1:])
 	}
 
 	wrapper, err := shell.Wrapper(shellName)
 	if err != nil {
 		return err
 	}
+	if *hook {
+		snippet, err := shell.Hook(shellName)
+		if err != nil {
+			return err
+		}
+		wrapper += snippet
+	}
 
 	fmt.Print(wrapper)
 	return nil
@@ -117,51 +198,208 @@ func runExec(query string) error {
 		return nil
 	}
 
+	// Perform filesystem and git work here; the wrapper only receives the final cd.
+	sh := shell.NewEmitter(shell.Target())
 	switch result.Action {
 	case "cd":
-		// Output cd command for shell to eval
-		fmt.Printf("cd %q\n", result.Path)
+		recordVisit(result.Path)
+		// Graduated entries jump to the real project
+		if result.DestPath != "" {
+			sh.Cd(result.DestPath)
+		} else {
+			sh.Cd(result.Path)
+		}
 	case "mkdir":
-		// Create directory and cd into it
-		fmt.Printf("mkdir -p %q && cd %q\n", result.Path, result.Path)
+		if err := os.MkdirAll(result.Path, 0755); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+		recordMeta(result.Path, meta.Origin{Kind: meta.OriginMkdir}, result.Tags...)
+		recordVisit(result.Path)
+		sh.Cd(result.Path)
+		addHook(sh, "hooks.post_create")
 	case "graduate":
-		// Move directory to projects and create symlink
-		symlinkPath := filepath.Join(entry.TriesPath(), result.BaseName)
-		// Check if source is a git worktree (has .git file, not directory)
-		gitFile := filepath.Join(result.Path, ".git")
-		info, err := os.Stat(gitFile)
-		isWorktree := err == nil && !info.IsDir()
-
-		if isWorktree {
-			// Use git worktree move for proper bookkeeping
-			fmt.Printf("git worktree move %q %q && ", result.Path, result.DestPath)
-		} else {
-			fmt.Printf("mv %q %q && ", result.Path, result.DestPath)
+		// A bulk graduate moves every entry into the folder at DestPath
+		if len(result.Bulk) > 0 {
+			if err := os.MkdirAll(result.DestPath, 0755); err != nil {
+				return fmt.Errorf("create folder: %w", err)
+			}
 		}
-		fmt.Printf("ln -s %q %q && ", result.DestPath, symlinkPath)
-		fmt.Printf("echo %q && ", fmt.Sprintf("Graduated: %s → %s", result.BaseName, result.DestPath))
-		fmt.Printf("cd %q\n", result.DestPath)
+		var errs []error
+		for _, r := range bulkResults(result) {
+			if err := graduateEntry(r); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+		sh.Cd(result.DestPath)
 	case "delete":
-		// Delete directory (stay in current directory or go to tries root)
-		triesPath := entry.TriesPath()
-		if result.IsWorktree {
-			// Use git worktree remove for proper cleanup (updates main repo's .git/worktrees/)
-			fmt.Printf("git worktree remove --force %q && ", result.Path)
-		} else {
-			fmt.Printf("rm -rf %q && ", result.Path)
+		var errs []error
+		for _, r := range bulkResults(result) {
+			if err := deleteEntry(r); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+		// If we were inside a deleted directory, go to its tries root
+		if _, err := os.Stat(os.Getenv("PWD")); err != nil {
+			sh.Cd(filepath.Dir(bulkResults(result)[0].Path))
+		}
+	case "archive":
+		var errs []error
+		for _, r := range bulkResults(result) {
+			if err := archiveEntry(r); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+		// If we were inside an archived directory, go to its tries root
+		if _, err := os.Stat(os.Getenv("PWD")); err != nil {
+			sh.Cd(filepath.Dir(bulkResults(result)[0].Path))
+		}
+	case "restore":
+		dest, err := restoreArchive(result.Path)
+		if err != nil {
+			return err
+		}
+		recordVisit(dest)
+		sh.Cd(dest)
+	case "demote":
+		if err := demoteEntry(result.Path, result.DestPath, result.DestPath); err != nil {
+			return err
 		}
-		fmt.Printf("echo %q && ", fmt.Sprintf("Deleted: %s", result.BaseName))
-		// If we're in the deleted directory, go to tries root
-		fmt.Printf("( cd %q 2>/dev/null || cd %q )\n", os.Getenv("PWD"), triesPath)
+		recordVisit(result.DestPath)
+		sh.Cd(result.DestPath)
 	case "rename":
-		// Rename directory and cd into it
-		triesPath := entry.TriesPath()
-		fmt.Printf("cd %q && ", triesPath)
-		fmt.Printf("mv %q %q && ", result.BaseName, result.NewName)
-		fmt.Printf("echo %q && ", fmt.Sprintf("Renamed: %s → %s", result.BaseName, result.NewName))
-		fmt.Printf("cd %q\n", result.DestPath)
+		for _, p := range []string{result.Path, result.DestPath} {
+			if err := entry.CheckContained(p); err != nil {
+				return fmt.Errorf("rename: %w", err)
+			}
+		}
+		if err := action.Rename(result.Path, result.DestPath); err != nil {
+			return fmt.Errorf("rename: %w", err)
+		}
+		if err := meta.Move(filepath.Dir(result.Path), result.BaseName, result.NewName); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: move metadata: %v\n", err)
+		}
+		updateVisits(filepath.Dir(result.Path), func(l *frecency.Log) { l.Rename(result.BaseName, result.NewName) })
+		fmt.Fprintf(os.Stderr, "Renamed: %s → %s\n", result.BaseName, result.NewName)
+		sh.Cd(result.DestPath)
+	}
+	fmt.Print(sh)
+
+	return nil
+}
+
+// bulkResults returns the per-entry results of a bulk action, or the
+// result itself for a single entry.
+func bulkResults(result *selector.Result) []selector.Result {
+	if len(result.Bulk) > 0 {
+		return result.Bulk
+	}
+	return []selector.Result{*result}
+}
+
+// graduateEntry moves an entry to its destination and leaves a symlink
+// behind, then bootstraps the project as asked and records the graduation
+// in the entry's metadata. Bootstrapping failures only warn: the project
+// has already moved.
+func graduateEntry(r selector.Result) error {
+	if err := entry.CheckContained(r.Path); err != nil {
+		return fmt.Errorf("graduate %s: %w", r.BaseName, err)
 	}
+	symlinkPath := filepath.Join(filepath.Dir(r.Path), r.BaseName)
+	graduate := func(progress action.Progress) error {
+		return action.Graduate(r.Path, r.DestPath, symlinkPath, progress)
+	}
+	var err error
+	if action.CrossDevice(r.Path, filepath.Dir(r.DestPath)) {
+		// Another filesystem: the project is copied, so show progress
+		err = selector.RunProgress("🚀 Copying "+r.BaseName+" to "+r.DestPath, func(report func(done, total int64) error) error {
+			return graduate(report)
+		})
+	} else {
+		err = graduate(nil)
+	}
+	if err != nil {
+		return fmt.Errorf("graduate %s: %w", r.BaseName, err)
+	}
+	fmt.Fprintf(os.Stderr, "Graduated: %s → %s\n", r.BaseName, r.DestPath)
 
+	g := &meta.Graduation{At: time.Now(), Path: r.DestPath}
+	if r.GitInit {
+		created, err := action.InitRepo(r.DestPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: git init %s: %v\n", r.DestPath, err)
+		}
+		g.GitInit = created
+	}
+	if r.Remote != "" {
+		if err := action.AddRemote(r.DestPath, r.Remote); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: remote %s: %v\n", r.Remote, err)
+		} else {
+			g.Remote = r.Remote
+			fmt.Fprintf(os.Stderr, "Pushed to %s\n", r.Remote)
+		}
+	}
+
+	root := filepath.Dir(r.Path)
+	m, _ := meta.Load(root, r.BaseName)
+	if m == nil {
+		m = &meta.Meta{}
+	}
+	m.Graduated = g
+	if err := meta.Save(root, r.BaseName, m); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: save metadata: %v\n", err)
+	}
+	return nil
+}
+
+// deleteEntry moves an entry (worktree-aware) with its metadata to the
+// trash and forgets its visits.
+func deleteEntry(r selector.Result) error {
+	if err := entry.CheckContained(r.Path); err != nil {
+		return fmt.Errorf("delete %s: %w", r.BaseName, err)
+	}
+	root := filepath.Dir(r.Path)
+	m, _ := meta.Load(root, r.BaseName)
+	if _, err := trash.Put(r.Path, m, time.Now()); err != nil {
+		return fmt.Errorf("delete %s: %w", r.BaseName, err)
+	}
+	if err := meta.Remove(root, r.BaseName); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: remove metadata: %v\n", err)
+	}
+	updateVisits(root, func(l *frecency.Log) { l.Forget(r.BaseName) })
+	fmt.Fprintf(os.Stderr, "Deleted: %s (try trash restore to undo)\n", r.BaseName)
+	expireTrash(root)
+	return nil
+}
+
+// runFilter prints entry paths ranked for query, one per line, using the
+// same ranking as the selector. It never opens /dev/tty.
+func runFilter(query string) error {
+	entries, err := entry.LoadAll()
+	if err != nil {
+		return fmt.Errorf("load entries: %w", err)
+	}
+
+	weights, err := rank.LoadWeights()
+	if err != nil {
+		return err
+	}
+
+	results := rank.Rank(entries, query, time.Now(), weights)
+	if len(results) == 0 {
+		return errNoMatch
+	}
+	for _, r := range results {
+		fmt.Println(r.Entry.Path)
+	}
 	return nil
 }
 
@@ -178,15 +416,24 @@ func runClone(gitURL string) error {
 	}
 
 	// Generate directory name: {date}-{user}-{repo}
-	datePrefix := time.Now().Format("2006-01-02")
+	datePrefix := entry.DatePrefix(time.Now())
 	dirName := fmt.Sprintf("%s-%s-%s", datePrefix, user, repo)
 	fullPath := filepath.Join(entry.TriesPath(), dirName)
 
+	// Create the directory before recording metadata, so metadata never
+	// outlives a directory that failed to appear; git clones into it.
+	if err := os.MkdirAll(fullPath, 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	recordMeta(fullPath, meta.Origin{Kind: meta.OriginClone, URL: gitURL})
+
 	// Output shell commands for clone
-	fmt.Printf("mkdir -p %q && ", fullPath)
-	fmt.Printf("echo %q && ", fmt.Sprintf("Using git clone to create this trial from %s.", gitURL))
-	fmt.Printf("git clone %q %q && ", gitURL, fullPath)
-	fmt.Printf("cd %q\n", fullPath)
+	sh := shell.NewEmitter(shell.Target())
+	sh.Echo(fmt.Sprintf("Using git clone to create this trial from %s.", gitURL))
+	sh.GitClone(gitURL, fullPath, cloneArgs()...)
+	sh.Cd(fullPath)
+	addHook(sh, "hooks.post_clone")
+	fmt.Print(sh)
 
 	return nil
 }
@@ -239,28 +486,67 @@ func runWorktree(args []string) error {
 	baseName = strings.ReplaceAll(baseName, " ", "-")
 
 	// Generate unique directory name
-	datePrefix := time.Now().Format("2006-01-02")
+	datePrefix := entry.DatePrefix(time.Now())
 	triesPath := entry.TriesPath()
 	finalName := resolveUniqueName(triesPath, datePrefix, baseName)
 	fullPath := filepath.Join(triesPath, fmt.Sprintf("%s-%s", datePrefix, finalName))
 
+	// Create the directory before recording metadata, as for clones;
+	// git worktree add accepts an empty directory.
+	if err := os.MkdirAll(fullPath, 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	origin := meta.Origin{Kind: meta.OriginMkdir}
+	if isGitRepo {
+		origin = meta.Origin{Kind: meta.OriginWorktree, SourceRepo: repoDir}
+	}
+	recordMeta(fullPath, origin)
+
 	// Output shell commands
+	sh := shell.NewEmitter(shell.Target())
 	if isGitRepo {
 		// Create worktree
-		fmt.Printf("mkdir -p %q && ", fullPath)
-		fmt.Printf("echo %q && ", fmt.Sprintf("Using git worktree to create this trial from %s.", repoDir))
-		// Use a subshell to handle worktree creation gracefully
-		fmt.Printf("(cd %q && git worktree add --detach %q 2>/dev/null || true) && ", repoDir, fullPath)
-		fmt.Printf("cd %q\n", fullPath)
+		sh.Echo(fmt.Sprintf("Using git worktree to create this trial from %s.", repoDir))
+		// Tolerate worktree failure so we still end up in the directory
+		sh.GitWorktreeAdd(repoDir, fullPath)
+		sh.Cd(fullPath)
 	} else {
-		// Not a git repo, just create directory
+		// Not a git repo, just the directory
 		fmt.Fprintf(os.Stderr, "Note: %s is not a git repository, creating plain directory.\n", pathArg)
-		fmt.Printf("mkdir -p %q && cd %q\n", fullPath, fullPath)
+		sh.Cd(fullPath)
 	}
+	addHook(sh, "hooks.post_create")
+	fmt.Print(sh)
 
 	return nil
 }
 
+// cloneArgs returns extra git clone arguments from clone.depth and clone.args.
+func cloneArgs() []string {
+	var args []string
+	if depth := config.Get("clone.depth"); depth != "0" {
+		args = append(args, "--depth", depth)
+	}
+	return append(args, config.GetList("clone.args")...)
+}
+
+// addHook appends the configured hook command, run by the shell after cd.
+func addHook(sh *shell.Emitter, key string) {
+	if hook := config.Get(key); hook != "" {
+		sh.Raw(hook)
+	}
+}
+
+// isPassthrough reports whether the wrapper runs cmd directly instead of through exec.
+func isPassthrough(cmd string) bool {
+	for _, pattern := range shell.Passthrough {
+		if ok, _ := filepath.Match(pattern, cmd); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // resolveUniqueName generates a unique directory name with versioning
 func resolveUniqueName(triesPath, datePrefix, baseName string) string {
 	initial := fmt.Sprintf("%s-%s", datePrefix, baseName)
@@ -343,7 +629,36 @@ Usage:
   try <name>           Jump to or create experiment
   try <git-url>        Auto-detect git URL and clone
   try init [shell]     Output shell wrapper function
+                       (--hook also records visits on every cd)
   try clone <url>      Clone repository into tries directory
+  try meta <name> [--description <text>]
+                       Show or edit experiment metadata
+  try tag <name> [+tag] [-tag]
+                       Show, add or remove tags (#tag in a query filters by tag)
+  try pin <name>       Pin an experiment to the top (unpin to undo)
+  try visit [path]     Record a visit for frecency (default: $PWD)
+  try --filter <query> Print ranked paths without the TUI (like fzf --filter)
+  try config path|list|get <key>|set <key> <value>
+                       Show or edit the config file (list --show-origin)
+  try -c <key>=<value> ...
+                       Override a config value for one run (repeatable)
+  try --profile <name> ...
+                       Use a profile's roots (comma-separated or "all" merges them)
+  try list [flags]     Print experiments (--format plain|tsv|json,
+                       --sort mtime|name, --reverse, --match <s>,
+                       --worktrees, --source <repo>, --tag <tag>, --limit <n>)
+  try du [--limit <n>] Show the biggest experiments and total disk usage
+  try clean [--older-than 30d] [--dry-run]
+                       Remove build artifacts (clean.patterns) from old experiments
+  try prune [--older-than 90d] [--archive | --delete | -i]
+                       List stale experiments (pinned and #keep are spared);
+                       --archive or --delete acts on them, -i reviews them in the selector
+  try trash list|restore [name]|empty
+                       Deleted experiments; restore without a name undoes the last delete
+  try archive <name>   Pack an experiment into .try/archive/<name>.tar.gz
+  try restore [name]   Unpack an archived experiment (lists archives without a name)
+  try demote <name|path>
+                       Move a graduated project (or any directory) back into the tries root
   try .                Create worktree from current git repo
   try . <name>         Create worktree with custom name
   try ./path           Create worktree from specified path
@@ -355,8 +670,23 @@ Examples:
   try https://github.com/user/repo  # Auto-detect and clone
   try .                     # Create worktree: 2024-01-15-reponame
   try . feature             # Create worktree: 2024-01-15-feature
+  try list --format json    # Machine-readable list for scripts
+  try tag redis-test +db    # Tag an experiment, then search "#db"
+
+Config:
+  ~/.config/try/config.toml (or $TRY_CONFIG); precedence: -c flags > env > file > defaults.
+  Keys: core.path, core.projects, core.date_format, rank.*, ui.theme, keys.*,
+  hooks.post_create, hooks.post_clone, clone.depth, clone.args, clean.patterns,
+  graduate.git_init, graduate.remote
+  (see try config list)
+  Profiles: [profile.<name>] tables with path and projects; core.profile picks one.
 
 Environment:
-  TRY_PATH      Root directory (default: ~/tries)
-  TRY_PROJECTS  Graduate destination (default: parent of TRY_PATH)`)
+  TRY_PATH          Root directory, core.path (default: ~/tries)
+  TRY_PROJECTS      Graduate destination, core.projects (default: parent of TRY_PATH)
+  TRY_DATE_FORMAT   Date prefix layout, core.date_format (default: 2006-01-02)
+  TRY_THEME         Selector colors, ui.theme: default or mono
+  TRY_PROFILE       Active profile(s), core.profile
+  TRY_RANK_WEIGHTS  Ranking weights, rank.* (default: match=1,recency=0.5,frecency=0.5,pin=1)
+  TRY_CONFIG        Config file location`)
 }
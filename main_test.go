@@ -1,12 +1,24 @@
 package main
 
 import (
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/xpzouying/try/internal/meta"
+	"github.com/xpzouying/try/internal/selector"
 )
 
+// TestMain keeps the developer's own config file out of the tests.
+func TestMain(m *testing.M) {
+	os.Setenv("TRY_CONFIG", filepath.Join(os.TempDir(), "try-test-no-config.toml"))
+	os.Unsetenv("TRY_PROFILE")
+	os.Exit(m.Run())
+}
+
 func TestContainsAny(t *testing.T) {
 	tests := []struct {
 		args     []string
@@ -376,12 +388,183 @@ func TestRun_WorktreeNonGit(t *testing.T) {
 		t.Error("should note that directory is not a git repository")
 	}
 
-	// Check stdout for mkdir command
+	// The directory is created before the wrapper cds into it
 	buf := make([]byte, 512)
 	n, _ := r.Read(buf)
 	output := string(buf[:n])
 
-	if !strings.Contains(output, "mkdir") {
-		t.Error("non-git worktree should create directory")
+	matches, _ := filepath.Glob(filepath.Join(tmpDir, "*-non-git"))
+	if len(matches) != 1 {
+		t.Fatalf("non-git worktree should create directory, found %v", matches)
+	}
+	if !strings.Contains(output, "cd '"+matches[0]+"'") {
+		t.Errorf("expected cd into %s, got %q", matches[0], output)
+	}
+}
+
+// Integration test: headless --filter ranking
+func TestRun_Filter(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+	for _, name := range []string{"2024-01-15-redis", "2024-01-16-postgres"} {
+		if err := os.Mkdir(filepath.Join(tmpDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{{"--filter", "redis"}, {"--filter=redis"}} {
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		err := run(args)
+
+		_ = w.Close()
+		os.Stdout = oldStdout
+
+		if err != nil {
+			t.Fatalf("run(%v) returned error: %v", args, err)
+		}
+
+		buf := make([]byte, 1024)
+		n, _ := r.Read(buf)
+		output := strings.TrimSpace(string(buf[:n]))
+
+		expected := filepath.Join(tmpDir, "2024-01-15-redis")
+		if output != expected {
+			t.Errorf("run(%v) = %q, expected %q", args, output, expected)
+		}
+	}
+}
+
+// Integration test: --filter without matches exits non-zero silently
+func TestRun_FilterNoMatch(t *testing.T) {
+	t.Setenv("TRY_PATH", t.TempDir())
+
+	err := run([]string{"--filter", "nothing"})
+	if !errors.Is(err, errNoMatch) {
+		t.Errorf("expected errNoMatch, got %v", err)
+	}
+}
+
+func TestBulkResults(t *testing.T) {
+	single := &selector.Result{Action: "delete", Path: "/tries/a"}
+	if got := bulkResults(single); len(got) != 1 || got[0].Path != "/tries/a" {
+		t.Errorf("expected the result itself, got %+v", got)
+	}
+
+	bulk := &selector.Result{Action: "delete", Bulk: []selector.Result{{Path: "/tries/a"}, {Path: "/tries/b"}}}
+	if got := bulkResults(bulk); len(got) != 2 {
+		t.Errorf("expected both bulk results, got %+v", got)
+	}
+}
+
+func TestDeleteAndGraduateEntry(t *testing.T) {
+	tmpDir := t.TempDir()
+	tries := filepath.Join(tmpDir, "tries")
+	t.Setenv("TRY_PATH", tries)
+	for _, name := range []string{"2024-01-15-a", "2024-01-16-b"} {
+		if err := os.MkdirAll(filepath.Join(tries, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		recordMeta(filepath.Join(tries, name), meta.Origin{Kind: meta.OriginMkdir})
+	}
+
+	err := deleteEntry(selector.Result{Path: filepath.Join(tries, "2024-01-15-a"), BaseName: "2024-01-15-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tries, "2024-01-15-a")); !os.IsNotExist(err) {
+		t.Error("directory should be deleted")
+	}
+	if m, _ := meta.Load(tries, "2024-01-15-a"); m != nil {
+		t.Error("metadata should be removed with the entry")
+	}
+
+	dest := filepath.Join(tmpDir, "projects", "b")
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = graduateEntry(selector.Result{Path: filepath.Join(tries, "2024-01-16-b"), DestPath: dest, BaseName: "2024-01-16-b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(filepath.Join(tries, "2024-01-16-b")); err != nil || target != dest {
+		t.Errorf("expected symlink to %s, got %q (%v)", dest, target, err)
+	}
+}
+
+func TestDeleteEntry_RefusesEscapes(t *testing.T) {
+	tmpDir := t.TempDir()
+	tries := filepath.Join(tmpDir, "tries")
+	outside := filepath.Join(tmpDir, "outside")
+	t.Setenv("TRY_PATH", tries)
+	for _, dir := range []string{tries, filepath.Join(outside, "keep")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(tries, "2024-01-15-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		tries,
+		filepath.Join(tries, "..", "outside", "keep"),
+		filepath.Join(tries, "2024-01-15-link", "keep"),
+	} {
+		if err := deleteEntry(selector.Result{Path: path, BaseName: filepath.Base(path)}); err == nil {
+			t.Errorf("expected delete of %s to be refused", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "keep")); err != nil {
+		t.Errorf("target outside the root should survive: %v", err)
+	}
+	if _, err := os.Stat(tries); err != nil {
+		t.Errorf("root should survive: %v", err)
+	}
+}
+
+func TestGraduateEntry_Bootstrap(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(v, "try")
+	}
+	for _, v := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(v, "try@example.com")
+	}
+
+	tmpDir := t.TempDir()
+	tries := filepath.Join(tmpDir, "tries")
+	t.Setenv("TRY_PATH", tries)
+	name := "2024-01-15-redis"
+	if err := os.MkdirAll(filepath.Join(tries, name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tries, name, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(tmpDir, "redis")
+	remote := filepath.Join(tmpDir, "git", "redis.git")
+	err := graduateEntry(selector.Result{
+		Path: filepath.Join(tries, name), DestPath: dest, BaseName: name,
+		GitInit: true, Remote: remote,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(remote, "HEAD")); err != nil {
+		t.Errorf("expected a bare remote: %v", err)
+	}
+	m, err := meta.Load(tries, name)
+	if err != nil || m == nil || m.Graduated == nil {
+		t.Fatalf("expected the graduation recorded, got %+v (%v)", m, err)
+	}
+	if g := m.Graduated; g.Path != dest || !g.GitInit || g.Remote != remote {
+		t.Errorf("unexpected graduation record: %+v", g)
 	}
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+// runMeta shows or edits an experiment's metadata.
+//
+//	try meta <name>                       Show metadata
+//	try meta <name> --description <text>  Set description ("" clears it)
+func runMeta(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("meta requires an experiment name")
+	}
+	name := args[0]
+
+	fs := flag.NewFlagSet("meta", flag.ContinueOnError)
+	description := fs.String("description", "", "set the description")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	e, err := findEntry(name)
+	if err != nil {
+		return err
+	}
+
+	descSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "description" {
+			descSet = true
+		}
+	})
+	if !descSet {
+		printMeta(e)
+		return nil
+	}
+
+	m := e.Meta
+	if m == nil {
+		m = &meta.Meta{}
+	}
+	m.Description = *description
+	if err := meta.Save(filepath.Dir(e.Path), e.Name, m); err != nil {
+		return fmt.Errorf("save metadata: %w", err)
+	}
+	return nil
+}
+
+// runTag shows or edits an experiment's tags.
+//
+//	try tag <name>               Show tags
+//	try tag <name> +go +db -old  Add go and db, remove old (bare words add)
+func runTag(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("tag requires an experiment name")
+	}
+
+	e, err := findEntry(args[0])
+	if err != nil {
+		return err
+	}
+
+	m := e.Meta
+	if m == nil {
+		m = &meta.Meta{}
+	}
+	if len(args) > 1 {
+		m.EditTags(args[1:])
+		if err := meta.Save(filepath.Dir(e.Path), e.Name, m); err != nil {
+			return fmt.Errorf("save metadata: %w", err)
+		}
+	}
+
+	for _, tag := range m.Tags {
+		fmt.Println(tag)
+	}
+	return nil
+}
+
+// runPin pins or unpins an experiment.
+func runPin(args []string, pinned bool) error {
+	if len(args) == 0 {
+		return fmt.Errorf("pin requires an experiment name")
+	}
+
+	e, err := findEntry(args[0])
+	if err != nil {
+		return err
+	}
+
+	m := e.Meta
+	if m == nil {
+		m = &meta.Meta{}
+	}
+	m.Pinned = pinned
+	if err := meta.Save(filepath.Dir(e.Path), e.Name, m); err != nil {
+		return fmt.Errorf("save metadata: %w", err)
+	}
+	return nil
+}
+
+func printMeta(e *entry.Entry) {
+	fmt.Printf("name:        %s\n", e.Name)
+	fmt.Printf("path:        %s\n", e.Path)
+	if e.Meta == nil {
+		fmt.Println("(no metadata recorded)")
+		return
+	}
+	m := e.Meta
+	if m.Description != "" {
+		fmt.Printf("description: %s\n", m.Description)
+	}
+	if len(m.Tags) > 0 {
+		fmt.Printf("tags:        %s\n", strings.Join(m.Tags, ", "))
+	}
+	if m.Pinned {
+		fmt.Println("pinned:      yes")
+	}
+	if m.Origin.Kind != "" {
+		fmt.Printf("origin:      %s\n", formatOrigin(m.Origin))
+	}
+	if !m.CreatedAt.IsZero() {
+		fmt.Printf("created:     %s\n", m.CreatedAt.Format(time.RFC3339))
+	}
+	if g := m.Graduated; g != nil {
+		fmt.Printf("graduated:   %s → %s\n", g.At.Format(time.RFC3339), g.Path)
+		if g.Remote != "" {
+			fmt.Printf("remote:      %s\n", g.Remote)
+		}
+	}
+}
+
+func formatOrigin(o meta.Origin) string {
+	switch {
+	case o.URL != "":
+		return fmt.Sprintf("%s %s", o.Kind, o.URL)
+	case o.SourceRepo != "":
+		return fmt.Sprintf("%s %s", o.Kind, o.SourceRepo)
+	case o.Template != "":
+		return fmt.Sprintf("%s %s", o.Kind, o.Template)
+	default:
+		return o.Kind
+	}
+}
+
+// findEntry resolves an experiment by directory name, or path, within the
+// active tries roots. Names are looked up in root order.
+func findEntry(name string) (*entry.Entry, error) {
+	roots := entry.Roots()
+	var path string
+	if filepath.IsAbs(name) {
+		path = filepath.Clean(name)
+		if entry.RootOf(path).Path != filepath.Dir(path) {
+			return nil, fmt.Errorf("not an experiment in %s: %s", roots[0].Path, name)
+		}
+	} else {
+		if strings.ContainsRune(name, filepath.Separator) || name == "" || name == "." || name == ".." {
+			return nil, fmt.Errorf("not an experiment in %s: %s", roots[0].Path, name)
+		}
+		path = filepath.Join(roots[0].Path, name)
+		for _, root := range roots {
+			if _, err := os.Lstat(filepath.Join(root.Path, name)); err == nil {
+				path = filepath.Join(root.Path, name)
+				break
+			}
+		}
+	}
+
+	e, err := entry.NewEntry(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no such experiment: %s", name)
+		}
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("not a directory: %s", name)
+	}
+	return e, nil
+}
+
+// recordMeta stores creation metadata for a new experiment.
+// Failure only warns: the experiment itself is still usable.
+func recordMeta(path string, origin meta.Origin, tags ...string) {
+	m := meta.New(origin)
+	for _, tag := range tags {
+		m.AddTag(tag)
+	}
+	if err := meta.Save(filepath.Dir(path), filepath.Base(path), m); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: record metadata: %v\n", err)
+	}
+}
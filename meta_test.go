@@ -0,0 +1,170 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+// Integration test: set and show a description
+func TestRun_Meta(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+	if err := os.Mkdir(filepath.Join(tmpDir, "2024-01-15-redis"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"meta", "2024-01-15-redis", "--description", "failover test"}); err != nil {
+		t.Fatalf("run(meta --description) returned error: %v", err)
+	}
+
+	m, err := meta.Load(tmpDir, "2024-01-15-redis")
+	if err != nil || m == nil {
+		t.Fatalf("expected saved metadata, got %v, %v", m, err)
+	}
+	if m.Description != "failover test" {
+		t.Errorf("expected description saved, got %q", m.Description)
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err = run([]string{"meta", "2024-01-15-redis"})
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("run(meta) returned error: %v", err)
+	}
+	out, _ := io.ReadAll(r)
+	if !strings.Contains(string(out), "description: failover test") {
+		t.Errorf("expected description in output, got %q", out)
+	}
+}
+
+func TestRun_MetaUnknownEntry(t *testing.T) {
+	t.Setenv("TRY_PATH", t.TempDir())
+
+	err := run([]string{"meta", "missing"})
+	if err == nil || !strings.Contains(err.Error(), "no such experiment") {
+		t.Errorf("expected no such experiment error, got %v", err)
+	}
+
+	for _, name := range []string{"../escape", ".", ".."} {
+		err = run([]string{"meta", name, "--description", "x"})
+		if err == nil || !strings.Contains(err.Error(), "not an experiment") {
+			t.Errorf("%s: expected not an experiment error, got %v", name, err)
+		}
+	}
+}
+
+// Integration test: clone records its origin
+func TestRun_CloneRecordsMeta(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+
+	oldStdout := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+	err := run([]string{"clone", "https://github.com/tobi/try"})
+	_ = w.Close()
+	os.Stdout = oldStdout
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(tmpDir, meta.Dir, "meta", "*-tobi-try.json"))
+	if len(matches) != 1 {
+		t.Fatalf("expected clone metadata, found %v", matches)
+	}
+	name := strings.TrimSuffix(filepath.Base(matches[0]), ".json")
+	m, err := meta.Load(tmpDir, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Origin.Kind != meta.OriginClone || m.Origin.URL != "https://github.com/tobi/try" {
+		t.Errorf("unexpected origin: %+v", m.Origin)
+	}
+	if m.CreatedAt.IsZero() {
+		t.Error("expected creation time")
+	}
+	if info, err := os.Stat(filepath.Join(tmpDir, name)); err != nil || !info.IsDir() {
+		t.Errorf("expected the directory created with its metadata, got %v", err)
+	}
+}
+
+// A clone whose directory can't be created leaves no metadata behind
+func TestRun_CloneFailedMkdir(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+	name := entry.DatePrefix(time.Now()) + "-tobi-try"
+	if err := os.WriteFile(filepath.Join(tmpDir, name), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"clone", "https://github.com/tobi/try"}); err == nil {
+		t.Fatal("expected error creating the directory")
+	}
+	if m, _ := meta.Load(tmpDir, name); m != nil {
+		t.Errorf("expected no metadata, got %+v", m)
+	}
+}
+
+// Integration test: add and remove tags
+func TestRun_Tag(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+	if err := os.Mkdir(filepath.Join(tmpDir, "2024-01-15-redis"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	oldStdout := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+	err1 := run([]string{"tag", "2024-01-15-redis", "+go", "db", "+old"})
+	err2 := run([]string{"tag", "2024-01-15-redis", "-old"})
+	_ = w.Close()
+	os.Stdout = oldStdout
+	if err1 != nil || err2 != nil {
+		t.Fatalf("run(tag) returned errors: %v, %v", err1, err2)
+	}
+
+	m, err := meta.Load(tmpDir, "2024-01-15-redis")
+	if err != nil || m == nil {
+		t.Fatalf("expected metadata, got %v, %v", m, err)
+	}
+	if strings.Join(m.Tags, ",") != "db,go" {
+		t.Errorf("expected tags db,go, got %v", m.Tags)
+	}
+}
+
+// Integration test: pin and unpin
+func TestRun_Pin(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+	if err := os.Mkdir(filepath.Join(tmpDir, "2024-01-15-redis"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"pin", "2024-01-15-redis"}); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := meta.Load(tmpDir, "2024-01-15-redis"); m == nil || !m.Pinned {
+		t.Error("expected entry to be pinned")
+	}
+
+	if err := run([]string{"unpin", "2024-01-15-redis"}); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := meta.Load(tmpDir, "2024-01-15-redis"); m == nil || m.Pinned {
+		t.Error("expected entry to be unpinned")
+	}
+}
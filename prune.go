@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/selector"
+)
+
+// keepTag exempts an experiment from pruning.
+const keepTag = "keep"
+
+// runPrune finds experiments untouched for longer than --older-than and
+// lists them (the default), archives or deletes them, or opens the
+// selector with them marked for review. Pinned experiments and those tagged #keep are spared.
+func runPrune(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	olderThan := fs.String("older-than", "90d", "only experiments not modified for this long (e.g. 90d, 12w)")
+	del := fs.Bool("delete", false, "delete the experiments instead of listing them")
+	archive := fs.Bool("archive", false, "archive the experiments instead of listing them")
+	interactive := fs.Bool("i", false, "review the experiments in the selector, all marked")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	modes := 0
+	for _, set := range []bool{*del, *archive, *interactive} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return fmt.Errorf("--delete, --archive and -i cannot be combined")
+	}
+
+	age, err := parseAge(*olderThan)
+	if err != nil {
+		return err
+	}
+	entries, err := entry.LoadAll()
+	if err != nil {
+		return fmt.Errorf("load entries: %w", err)
+	}
+	now := time.Now()
+	stale := pruneCandidates(entries, now.Add(-age))
+	if len(stale) == 0 {
+		fmt.Println("Nothing to prune")
+		return nil
+	}
+
+	switch {
+	case *interactive:
+		result, err := selector.RunMarked(stale)
+		if err != nil {
+			return err
+		}
+		var apply func(selector.Result) error
+		switch {
+		case result != nil && result.Action == "delete":
+			apply = deleteEntry
+		case result != nil && result.Action == "archive":
+			apply = archiveEntry
+		default:
+			fmt.Println("Nothing pruned")
+			return nil
+		}
+		var errs []error
+		for _, r := range bulkResults(result) {
+			errs = append(errs, apply(r))
+		}
+		return errors.Join(errs...)
+
+	case *del, *archive:
+		apply := deleteEntry
+		if *archive {
+			apply = archiveEntry
+		}
+		var errs []error
+		for _, e := range stale {
+			errs = append(errs, apply(selector.Result{
+				Path:       e.Path,
+				BaseName:   e.Name,
+				IsWorktree: e.IsWorktree,
+			}))
+		}
+		return errors.Join(errs...)
+
+	default:
+		writePruneList(os.Stdout, stale, now)
+		fmt.Printf("%d experiments not modified in %s (dry run; --archive or --delete acts on them, -i reviews them)\n", len(stale), *olderThan)
+		return nil
+	}
+}
+
+// pruneCandidates returns the entries last modified before cutoff that
+// are neither pinned, tagged #keep nor graduated.
+func pruneCandidates(entries []*entry.Entry, cutoff time.Time) []*entry.Entry {
+	var stale []*entry.Entry
+	for _, e := range entries {
+		if e.ModTime.After(cutoff) || e.Pinned() || e.HasTags([]string{keepTag}) || e.Graduated() {
+			continue
+		}
+		stale = append(stale, e)
+	}
+	return stale
+}
+
+// writePruneList prints each entry with its age in days.
+func writePruneList(w io.Writer, entries []*entry.Entry, now time.Time) {
+	for _, e := range entries {
+		name := e.Name
+		if e.Profile != "" {
+			name = "[" + e.Profile + "] " + name
+		}
+		days := int(now.Sub(e.ModTime).Hours() / 24)
+		fmt.Fprintf(w, "%5dd  %s\n", days, name)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xpzouying/try/internal/archive"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/meta"
+)
+
+func TestPruneCandidates(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-100 * 24 * time.Hour)
+	entries := []*entry.Entry{
+		{Name: "2024-01-01-old", ModTime: old},
+		{Name: "2024-05-30-new", ModTime: now.Add(-48 * time.Hour)},
+		{Name: "2024-01-02-pinned", ModTime: old, Meta: &meta.Meta{Pinned: true}},
+		{Name: "2024-01-03-kept", ModTime: old, Meta: &meta.Meta{Tags: []string{"db", "keep"}}},
+		{Name: "2024-01-04-tagged", ModTime: old, Meta: &meta.Meta{Tags: []string{"db"}}},
+	}
+
+	var names []string
+	for _, e := range pruneCandidates(entries, now.Add(-90*24*time.Hour)) {
+		names = append(names, e.Name)
+	}
+	if strings.Join(names, " ") != "2024-01-01-old 2024-01-04-tagged" {
+		t.Errorf("pruneCandidates() = %v", names)
+	}
+}
+
+func TestRun_Prune(t *testing.T) {
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+	old := time.Now().Add(-120 * 24 * time.Hour)
+
+	for _, name := range []string{"2024-01-10-stale", "2024-01-11-kept", "2024-01-12-fresh"} {
+		if err := os.Mkdir(filepath.Join(triesPath, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := meta.Save(triesPath, "2024-01-11-kept", &meta.Meta{Tags: []string{"keep"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"2024-01-10-stale", "2024-01-11-kept"} {
+		if err := os.Chtimes(filepath.Join(triesPath, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Dry run by default
+	out, err := captureRun(t, "prune", "--older-than", "90d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "120d  2024-01-10-stale\n") || strings.Contains(out, "kept") || strings.Contains(out, "fresh") {
+		t.Errorf("unexpected dry run output:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(triesPath, "2024-01-10-stale")); err != nil {
+		t.Fatal("dry run deleted an experiment")
+	}
+
+	if _, err := captureRun(t, "prune", "--older-than", "90d", "--delete"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(triesPath, "2024-01-10-stale")); !os.IsNotExist(err) {
+		t.Error("expected stale experiment deleted")
+	}
+	for _, name := range []string{"2024-01-11-kept", "2024-01-12-fresh"} {
+		if _, err := os.Stat(filepath.Join(triesPath, name)); err != nil {
+			t.Errorf("expected %s kept", name)
+		}
+	}
+
+	if _, err := captureRun(t, "prune", "--delete", "-i"); err == nil {
+		t.Error("expected error combining --delete and -i")
+	}
+}
+
+func TestRun_PruneArchive(t *testing.T) {
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+	old := time.Now().Add(-120 * 24 * time.Hour)
+
+	dir := filepath.Join(triesPath, "2024-01-10-stale")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(dir, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := captureRun(t, "prune", "--archive"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("expected stale experiment removed")
+	}
+	if _, err := os.Stat(archive.PathFor(triesPath, "2024-01-10-stale")); err != nil {
+		t.Errorf("expected archive: %v", err)
+	}
+}
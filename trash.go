@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/xpzouying/try/internal/config"
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/meta"
+	"github.com/xpzouying/try/internal/trash"
+)
+
+// runTrash lists, restores or purges deleted experiments.
+func runTrash(args []string) error {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	for _, root := range entry.Roots() {
+		expireTrash(root.Path)
+	}
+	items, err := trashItems()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		now := time.Now()
+		for _, it := range items {
+			fmt.Printf("%-40s  %s  %s\n", it.ID, formatDeleted(now, it.DeletedAt), it.Origin)
+		}
+		return nil
+
+	case "restore":
+		if len(args) == 1 {
+			// Undo the most recent delete
+			if len(items) == 0 {
+				return fmt.Errorf("trash is empty")
+			}
+			return restoreTrashItem(items[0])
+		}
+		var errs []error
+		for _, name := range args[1:] {
+			it, err := findTrashItem(items, name)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			errs = append(errs, restoreTrashItem(it))
+		}
+		return errors.Join(errs...)
+
+	case "empty":
+		var errs []error
+		for _, it := range items {
+			if err := entry.CheckContained(it.Path()); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if err := trash.Remove(it); err != nil {
+				errs = append(errs, fmt.Errorf("remove %s: %w", it.ID, err))
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Emptied trash: %d experiments removed\n", len(items)-len(errs))
+		return errors.Join(errs...)
+
+	default:
+		return fmt.Errorf("unknown trash command: %s (supported: list, restore, empty)", args[0])
+	}
+}
+
+// trashItems returns the trash of every active root, most recent first.
+func trashItems() ([]*trash.Item, error) {
+	var items []*trash.Item
+	for _, root := range entry.Roots() {
+		rootItems, err := trash.List(root.Path)
+		if err != nil {
+			return nil, fmt.Errorf("list trash: %w", err)
+		}
+		items = append(items, rootItems...)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// findTrashItem picks the item with the given ID, else the most recent
+// deletion of the given experiment name.
+func findTrashItem(items []*trash.Item, name string) (*trash.Item, error) {
+	for _, it := range items {
+		if it.ID == name {
+			return it, nil
+		}
+	}
+	for _, it := range items {
+		if it.Name == name {
+			return it, nil
+		}
+	}
+	return nil, fmt.Errorf("not in the trash: %s", name)
+}
+
+// restoreTrashItem moves an item back with its metadata.
+func restoreTrashItem(it *trash.Item) error {
+	if err := entry.CheckContained(it.Origin); err != nil {
+		return fmt.Errorf("restore %s: %w", it.Name, err)
+	}
+	if err := trash.Restore(it); err != nil {
+		return err
+	}
+	if it.Meta != nil {
+		if err := meta.Save(filepath.Dir(it.Origin), it.Name, it.Meta); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: restore metadata: %v\n", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Restored: %s\n", it.Origin)
+	return nil
+}
+
+// expireTrash purges items older than trash.expire_days from a root's
+// trash. Failure only warns: expiry is housekeeping.
+func expireTrash(root string) {
+	days, _ := strconv.Atoi(config.Get("trash.expire_days"))
+	if days <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	if _, err := trash.Expire(root, cutoff); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: expire trash: %v\n", err)
+	}
+}
+
+// formatDeleted renders how long ago an item was deleted.
+func formatDeleted(now, t time.Time) string {
+	switch d := now.Sub(t); {
+	case d < time.Hour:
+		return fmt.Sprintf("%3dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%3dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%3dd ago", int(d.Hours()/24))
+	}
+}
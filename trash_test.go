@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xpzouying/try/internal/meta"
+	"github.com/xpzouying/try/internal/selector"
+	"github.com/xpzouying/try/internal/trash"
+)
+
+func TestRun_Trash(t *testing.T) {
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+
+	name := "2024-01-15-redis"
+	dir := filepath.Join(triesPath, name)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := meta.Save(triesPath, name, &meta.Meta{Description: "cache test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deleteEntry(selector.Result{Path: dir, BaseName: name}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatal("expected entry moved to the trash")
+	}
+
+	out, err := captureRun(t, "trash", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "-"+name) || !strings.Contains(out, dir) {
+		t.Errorf("unexpected list output: %q", out)
+	}
+
+	// Restore without a name undoes the last delete
+	if _, err := captureRun(t, "trash", "restore"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatal("expected entry restored")
+	}
+	if m, _ := meta.Load(triesPath, name); m == nil || m.Description != "cache test" {
+		t.Errorf("metadata not restored: %+v", m)
+	}
+
+	if err := deleteEntry(selector.Result{Path: dir, BaseName: name}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := captureRun(t, "trash", "empty"); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := trash.List(triesPath); len(items) != 0 {
+		t.Errorf("expected empty trash, got %d items", len(items))
+	}
+
+	if _, err := captureRun(t, "trash", "restore", name); err == nil {
+		t.Error("expected error restoring from an empty trash")
+	}
+	if _, err := captureRun(t, "trash", "shred"); err == nil {
+		t.Error("expected error for unknown subcommand")
+	}
+}
+
+func TestExpireTrash(t *testing.T) {
+	triesPath := t.TempDir()
+	t.Setenv("TRY_PATH", triesPath)
+
+	dir := filepath.Join(triesPath, "old")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trash.Put(dir, nil, time.Now().Add(-40*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := captureRun(t, "-c", "trash.expire_days=0", "trash", "list"); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := trash.List(triesPath); len(items) != 1 {
+		t.Fatal("expire_days=0 should keep items")
+	}
+
+	if _, err := captureRun(t, "trash", "list"); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := trash.List(triesPath); len(items) != 0 {
+		t.Error("expected item older than 30 days purged")
+	}
+}
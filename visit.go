@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xpzouying/try/internal/entry"
+	"github.com/xpzouying/try/internal/frecency"
+)
+
+// runVisit records a visit to the experiment containing path (default $PWD).
+// Paths outside every tries root are ignored, so the shell hook can
+// call it on every directory change.
+func runVisit(args []string) error {
+	path := os.Getenv("PWD")
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if path == "" {
+		return nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	// Any profile's root counts, so the hook works whichever profile is active
+	for _, root := range entry.AllRoots() {
+		if name := experimentName(root.Path, abs); name != "" {
+			return frecency.Update(root.Path, func(l *frecency.Log) {
+				l.Record(name, time.Now())
+			})
+		}
+	}
+	return nil
+}
+
+// experimentName returns the top-level entry under triesPath that contains
+// path, or "" if path is outside the tries directory or in a hidden entry.
+func experimentName(triesPath, path string) string {
+	rel, err := filepath.Rel(triesPath, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	name := strings.Split(rel, string(filepath.Separator))[0]
+	if strings.HasPrefix(name, ".") {
+		return ""
+	}
+	return name
+}
+
+// recordVisit counts a visit to the entry at path. Failure only warns.
+func recordVisit(path string) {
+	updateVisits(filepath.Dir(path), func(l *frecency.Log) {
+		l.Record(filepath.Base(path), time.Now())
+	})
+}
+
+// updateVisits applies fn to the visit log of root. Failure only warns:
+// visit history is a ranking hint, never worth failing a command over.
+func updateVisits(root string, fn func(l *frecency.Log)) {
+	if err := frecency.Update(root, fn); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: update visit log: %v\n", err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xpzouying/try/internal/frecency"
+)
+
+func TestExperimentName(t *testing.T) {
+	tries := "/home/u/tries"
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/home/u/tries/2024-01-15-redis", "2024-01-15-redis"},
+		{"/home/u/tries/2024-01-15-redis/src/pkg", "2024-01-15-redis"},
+		{"/home/u/tries", ""},
+		{"/home/u/tries/.try", ""},
+		{"/home/u/projects/redis", ""},
+		{"/home/u/tries-other/x", ""},
+	}
+
+	for _, tc := range tests {
+		if got := experimentName(tries, tc.path); got != tc.expected {
+			t.Errorf("experimentName(%s) = %q, expected %q", tc.path, got, tc.expected)
+		}
+	}
+}
+
+// Integration test: visit records frecency for experiments only
+func TestRun_Visit(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TRY_PATH", tmpDir)
+	exp := filepath.Join(tmpDir, "2024-01-15-redis")
+	if err := os.MkdirAll(filepath.Join(exp, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"visit", filepath.Join(exp, "src")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"visit", t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := frecency.Load(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Visits) != 1 || l.Get("2024-01-15-redis").Count != 1 {
+		t.Errorf("expected one visit to the experiment, got %+v", l.Visits)
+	}
+}